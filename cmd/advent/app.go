@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/navigation"
+)
+
+// Screen is one screen of the door. The App calls Enter when the caller
+// arrives on it, Render whenever what it shows changes, HandleKey for each
+// key pressed on it and Leave when the caller moves on. Screens move the
+// caller by changing the App's state through the navigator.
+type Screen interface {
+	Enter(a *App)
+	HandleKey(a *App, char rune, key input.Key)
+	Render(a *App)
+	Leave(a *App)
+}
+
+// ticker is a Screen that changes on its own while waiting for a key, such
+// as a countdown. Tick is called every tickInterval until a key comes.
+type ticker interface {
+	Tick(a *App)
+}
+
+// tickInterval is how often a ticker is ticked
+const tickInterval = time.Second
+
+// basicScreen gives screens that need no setting up or tearing down empty
+// Enter and Leave methods
+type basicScreen struct{}
+
+func (basicScreen) Enter(a *App) {}
+func (basicScreen) Leave(a *App) {}
+
+// hotkey opens one of the sysop's own screens
+type hotkey struct {
+	name  string
+	level int // Security level needed
+}
+
+// App runs one caller's session as a state machine: the navigation state
+// picks the Screen, and each key goes to the screen on view
+type App struct {
+	*door
+	state     navigation.State
+	screens   map[navigation.ScreenType]Screen
+	custom    map[string]Screen // The sysop's screens by name
+	hotkeys   map[rune]hotkey   // By upper-case key
+	current   Screen
+	viewer    *display.Viewer // Scrolls the screen when it's taller than the terminal
+	redraw    bool
+	done      bool
+	scheduler *navigation.Scheduler // Opens days that unlock while the caller is here
+
+	// Years unlocked with a key this session, for callers whose visits
+	// aren't remembered
+	keysEntered map[int]bool
+	showCredits bool // Toggled with C on day screens
+}
+
+// newApp returns an App for d's caller starting at state, with the built-in
+// screens and the sysop's screens from the config registered
+func newApp(d *door, state navigation.State) *App {
+	a := &App{
+		door:        d,
+		state:       state,
+		screens:     make(map[navigation.ScreenType]Screen),
+		custom:      make(map[string]Screen),
+		hotkeys:     make(map[rune]hotkey),
+		scheduler:   navigation.NewScheduler(d.navigator),
+		keysEntered: make(map[int]bool),
+	}
+	a.Register(navigation.ScreenWelcome, &welcomeScreen{})
+	a.Register(navigation.ScreenComeback, &comebackScreen{})
+	a.Register(navigation.ScreenDay, &dayScreen{})
+	a.Register(navigation.ScreenInfo, &pageScreen{kind: "info"})
+	a.Register(navigation.ScreenMembers, &pageScreen{kind: "members"})
+	a.Register(navigation.ScreenCalendar, &calendarScreen{picker: dayPicker{navigator: d.navigator, displayEngine: d.displayEngine}})
+	a.Register(navigation.ScreenYearSelect, &yearSelectScreen{})
+	a.Register(navigation.ScreenNotYet, &notYetScreen{})
+	a.Register(navigation.ScreenExit, &goodbyeScreen{})
+
+	for _, sc := range d.screens {
+		a.RegisterCustom(sc.Name, sc.Hotkey(), sc.Level, &pageScreen{path: "art/" + sc.Art})
+	}
+	return a
+}
+
+// Register sets the screen shown for a screen type, replacing any before it
+func (a *App) Register(screen navigation.ScreenType, s Screen) {
+	a.screens[screen] = s
+}
+
+// RegisterCustom adds one of the sysop's own screens under name, opened with
+// key from the welcome, comeback and day screens by callers at level or above
+func (a *App) RegisterCustom(name string, key rune, level int, s Screen) {
+	a.custom[name] = s
+	a.hotkeys[key] = hotkey{name: name, level: level}
+	logrus.WithFields(logrus.Fields{"screen": name, "key": string(key)}).Debug("Registered sysop screen")
+}
+
+// Quit ends the session once the current key or tick has been handled
+func (a *App) Quit() {
+	a.done = true
+}
+
+// Redraw has the current screen rendered again, such as after a setting it
+// shows has changed
+func (a *App) Redraw() {
+	a.redraw = true
+}
+
+// Run shows screens and hands them keys until one quits or the caller hangs up
+func (a *App) Run() {
+	loopStart := time.Now()
+	logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Starting first iteration")
+
+	for {
+		a.show()
+		if a.done {
+			break
+		}
+
+		// CRITICAL: Give Mystic BBS time to transmit buffered output to user's terminal before blocking on input
+		// Windows 7 + Mystic BBS needs significant time to flush output buffers
+		time.Sleep(500 * time.Millisecond)
+
+		char, key, err := a.readKey()
+		if err != nil {
+			// The caller hung up (or the BBS closed our input) - end the session
+			logrus.WithError(err).Info("Input closed, ending session")
+			break
+		}
+		if a.done {
+			break // A ticking screen timed out
+		}
+		logrus.WithField("elapsed", time.Since(loopStart)).Debug("MAINLOOP: Got user input")
+		a.handleKey(char, key)
+	}
+
+	if a.current != nil {
+		a.current.Leave(a)
+	}
+}
+
+// show switches to the screen for the current state, rendering it if it's
+// new or what it shows has changed
+func (a *App) show() {
+	screen := a.screen()
+	if screen != a.current {
+		if a.current != nil {
+			a.current.Leave(a)
+		}
+		logrus.WithFields(logrus.Fields{
+			"screen": a.state.Screen,
+			"year":   a.state.CurrentYear,
+			"day":    a.state.CurrentDay,
+		}).Debug("Entering screen")
+		a.current = screen
+		screen.Enter(a)
+		a.redraw = true
+	}
+	if !a.redraw || a.done {
+		return
+	}
+	a.redraw = false
+
+	a.viewer = nil
+	screen.Render(a)
+
+	// The countdown and goodbye screens aren't somewhere the caller browses to
+	if a.state.Screen != navigation.ScreenNotYet && a.state.Screen != navigation.ScreenExit {
+		a.visits.viewed(a.state, a.presence)
+	}
+}
+
+// screen returns the registered screen for the current state, falling back
+// to the welcome screen
+func (a *App) screen() Screen {
+	if a.state.Screen == navigation.ScreenCustom {
+		if s, ok := a.custom[a.state.Custom]; ok {
+			return s
+		}
+	} else if s, ok := a.screens[a.state.Screen]; ok {
+		return s
+	}
+	logrus.WithFields(logrus.Fields{"screen": a.state.Screen, "custom": a.state.Custom}).Error("No screen registered")
+	a.state.Screen = navigation.ScreenWelcome
+	return a.screens[navigation.ScreenWelcome]
+}
+
+// handleKey passes a key to the viewer, then to the current screen, and has
+// the screen redrawn if the key changed what it shows
+func (a *App) handleKey(char rune, key input.Key) {
+	a.sessionManager.ResetIdleTimer()
+
+	// Scroll and search screens taller than the terminal
+	if a.viewer != nil && viewKey(a.viewer, a.displayEngine, a.inputHandler, a.sessionManager, char, key) {
+		return
+	}
+
+	before := view(a.state)
+	a.current.HandleKey(a, char, key)
+	if view(a.state) != before {
+		a.redraw = true
+	}
+}
+
+// view identifies what a state shows, so a change is drawn
+func view(state navigation.State) string {
+	return fmt.Sprintf("%s:%s:%d:%d:%d:%d", state.Screen, state.Custom,
+		state.CurrentYear, state.CurrentDay, state.MaxDay, state.Cursor)
+}
+
+// resizeWait is how long readKey waits at a time when only a resize can
+// come before the caller's key
+const resizeWait = time.Hour
+
+// readKey waits for the caller's next key. Days that unlock in the meantime
+// are opened and announced with a toast over the current screen, a ticking
+// screen is ticked, and the screen is redrawn if the caller's terminal is
+// resized; the session's idle and max timers are left to run as usual. It
+// returns no key once a tick quits.
+func (a *App) readKey() (rune, input.Key, error) {
+	for !a.done {
+		t, ticking := a.current.(ticker)
+		wait := tickInterval
+		if !ticking {
+			scheduled, ok := a.scheduler.Wait(a.state)
+			switch {
+			case ok:
+				// Wake a moment after the boundary so the new day is already open
+				wait = scheduled + time.Second
+			case a.terminalSize == nil:
+				return a.inputHandler.ReadKey()
+			default:
+				wait = resizeWait
+			}
+		}
+
+		char, key, err := a.inputHandler.ReadKeyTimeout(wait)
+		if err == input.ErrWoken {
+			a.resize()
+			continue
+		}
+		if err != input.ErrTimeout {
+			return char, key, err
+		}
+		if ticking {
+			t.Tick(a)
+			continue
+		}
+		a.openNewDays()
+	}
+	return 0, input.KeyUnknown, nil
+}
+
+// resize redraws the current screen for the caller's new terminal size
+func (a *App) resize() {
+	if a.terminalSize == nil {
+		return
+	}
+	width, height := a.terminalSize()
+	if w, h := a.displayEngine.GetDimensions(); width <= 0 || height <= 0 || (w == width && h == height) {
+		return
+	}
+	logrus.WithFields(logrus.Fields{"width": width, "height": height}).Info("Caller's terminal resized")
+	a.displayEngine.SetSize(width, height)
+	a.user.W, a.user.H = width, height
+	a.user.ModalW, a.user.ModalH = width, height
+	a.Redraw()
+	a.show()
+}
+
+// openNewDays opens days that have unlocked since the state was last
+// brought up to date, announcing them with a toast
+func (a *App) openNewDays() {
+	opened := a.scheduler.Apply(&a.state)
+	if len(opened) == 0 {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"opened": opened,
+		"maxDay": a.state.MaxDay,
+	}).Info("New day unlocked during session")
+
+	if len(opened) == 1 {
+		a.displayEngine.Toast(fmt.Sprintf("Day %d is now open!", opened[0]))
+	} else {
+		a.displayEngine.Toast(fmt.Sprintf("Days %d-%d are now open!", opened[0], opened[len(opened)-1]))
+	}
+}
+
+// sysopKey opens the hidden sysop menu for '!', redrawing the current
+// screen after it. It reports whether it used the key.
+func (a *App) sysopKey(char rune) bool {
+	if char != sysopMenuKey || a.sysop == nil {
+		return false
+	}
+	a.sysop.open(&a.state)
+	a.redraw = true
+	return true
+}
+
+// yearOpen reports whether the caller can go to a year without being asked
+// for anything: their security level opens it and it isn't locked behind a
+// key they haven't entered
+func (a *App) yearOpen(year int) bool {
+	return a.user.SecurityLevel >= a.validator.YearLevel(year) &&
+		(!a.validator.RequireKey(year) || a.yearUnlocked(year))
+}
+
+// startInOpenYear moves the caller to the newest year open to them when the
+// one they start in isn't, and reports false when no year is
+func (a *App) startInOpenYear() bool {
+	if a.yearOpen(a.state.CurrentYear) {
+		return true
+	}
+	years := a.state.AvailableYears
+	for i := len(years) - 1; i >= 0; i-- {
+		if !a.yearOpen(years[i]) {
+			continue
+		}
+		logrus.WithFields(logrus.Fields{"from": a.state.CurrentYear, "year": years[i]}).Info("Starting in the newest year open to the caller")
+		a.state, _, _ = a.navigator.SelectYear(years[i], a.state)
+		return true
+	}
+	return false
+}
+
+// yearAllowed checks the caller's security level opens a year, asking for
+// the year's key if it's locked
+func (a *App) yearAllowed(year int) bool {
+	if level := a.validator.YearLevel(year); a.user.SecurityLevel < level {
+		logrus.WithFields(logrus.Fields{"year": year, "required": level}).Info("Year restricted by security level")
+		a.displayEngine.UpdateNotice(fmt.Sprintf("%d needs security level %d", year, level))
+		return false
+	}
+	if a.validator.RequireKey(year) && !a.yearUnlocked(year) {
+		if !promptKey(a.displayEngine, a.inputHandler, a.sessionManager, a.validator, year) {
+			return false
+		}
+		a.keysEntered[year] = true
+		a.visits.unlock(year)
+	}
+	return true
+}
+
+// yearUnlocked reports whether the caller has entered a year's key
+func (a *App) yearUnlocked(year int) bool {
+	return a.keysEntered[year] || a.visits.unlocked(year)
+}
+
+// display shows an art file, scrolling it when it's taller than the screen
+func (a *App) display(path string) {
+	if path == "" {
+		return
+	}
+	if err := a.displayEngine.Display(path, a.user); err != nil {
+		logrus.WithError(err).Error("Failed to display art")
+	}
+	a.viewer = a.displayEngine.Viewer() // Set when the art is taller than the screen
+}
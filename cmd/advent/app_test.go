@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/clock"
+	"github.com/robbiew/advent/internal/config"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/navigation"
+	"github.com/robbiew/advent/internal/session"
+	"github.com/robbiew/advent/internal/validation"
+)
+
+// testArt is a 2024 and a 2025 calendar, with a rules screen for the sysop
+var testArt = fstest.MapFS{
+	"art/2024/WELCOME.ANS":  {Data: []byte("WELCOME 2024")},
+	"art/2024/GOODBYE.ANS":  {Data: []byte("GOODBYE 2024")},
+	"art/2025/WELCOME.ANS":  {Data: []byte("WELCOME 2025")},
+	"art/2025/COMEBACK.ANS": {Data: []byte("COMEBACK 2025")},
+	"art/2025/GOODBYE.ANS":  {Data: []byte("GOODBYE 2025")},
+	"art/2025/12_DEC25.ANS": {Data: []byte("DAY 12")},
+	"art/common/RULES.ANS":  {Data: []byte("RULES")},
+}
+
+// newTestApp returns an App on the welcome screen of 2025, open to day 12,
+// for a caller at level reading keys from in, with what it draws in out
+func newTestApp(t *testing.T, level int, access validation.Access, in io.Reader, out *bytes.Buffer) *App {
+	t.Helper()
+	clk := clock.Fixed(time.Date(2025, 12, 12, 12, 0, 0, 0, time.Local))
+	artManager, navigator, validator := newCalendarComponents(testArt, calendar.NewResolver(testArt, "art"), clk)
+	validator.SetAccess(access)
+
+	displayEngine := display.NewDisplayEngine(display.DisplayConfig{Mode: display.ModeCP437Raw, Width: 80, Height: 25}, testArt)
+	displayEngine.SetBBSConnection(out)
+	inputHandler := input.NewInputHandler()
+	inputHandler.SetReader(in)
+
+	d := &door{
+		artManager:     artManager,
+		navigator:      navigator,
+		validator:      validator,
+		displayEngine:  displayEngine,
+		inputHandler:   inputHandler,
+		sessionManager: session.NewManager(time.Hour, time.Hour, nil, nil),
+		user:           display.User{Alias: "Tester", SecurityLevel: level, W: 80, H: 25},
+		screens:        []config.ScreenConfig{{Name: "rules", Key: "R", Art: "common/RULES.ANS", Level: 20}},
+	}
+	state, err := navigator.GetInitialState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.CurrentYear != 2025 || state.MaxDay != 12 {
+		t.Fatalf("initial state = %d day %d, want 2025 open to day 12", state.CurrentYear, state.MaxDay)
+	}
+	return newApp(d, state)
+}
+
+// press hands the App a key and shows whatever it leads to
+func press(a *App, char rune, key input.Key) {
+	a.handleKey(char, key)
+	a.show()
+}
+
+func TestAppWelcomeDayComeback(t *testing.T) {
+	var out bytes.Buffer
+	a := newTestApp(t, 10, validation.Access{}, strings.NewReader(""), &out)
+	a.state.CurrentDay = 12 // Where a returning caller left off
+
+	a.show()
+	if _, ok := a.current.(*welcomeScreen); !ok || !strings.Contains(out.String(), "WELCOME 2025") {
+		t.Fatalf("first screen = %T, want the 2025 welcome screen drawn", a.current)
+	}
+
+	out.Reset()
+	press(a, 0, input.KeyEnter)
+	if _, ok := a.current.(*dayScreen); !ok || a.state.CurrentDay != 12 || !strings.Contains(out.String(), "DAY 12") {
+		t.Fatalf("Enter on welcome = %T day %d, want day 12 drawn", a.current, a.state.CurrentDay)
+	}
+
+	out.Reset()
+	press(a, 0, input.KeyArrowRight)
+	if _, ok := a.current.(*comebackScreen); !ok || !strings.Contains(out.String(), "COMEBACK 2025") {
+		t.Fatalf("right from the newest day = %T, want the comeback screen drawn", a.current)
+	}
+
+	// A key that changes nothing doesn't redraw the screen
+	out.Reset()
+	press(a, 'z', input.KeyUnknown)
+	if out.Len() != 0 {
+		t.Errorf("unused key redrew the screen: %q", out.String())
+	}
+}
+
+func TestAppBackToNewestYear(t *testing.T) {
+	var out bytes.Buffer
+	a := newTestApp(t, 10, validation.Access{}, strings.NewReader(""), &out)
+	a.show()
+
+	press(a, '1', input.KeyUnknown)
+	if a.state.CurrentYear != 2024 || a.state.Screen != navigation.ScreenWelcome {
+		t.Fatalf("1 = %d %s, want 2024 welcome", a.state.CurrentYear, a.state.Screen)
+	}
+	press(a, 'q', input.KeyUnknown)
+	if a.state.CurrentYear != 2025 || a.state.Screen != navigation.ScreenWelcome {
+		t.Fatalf("Q from 2024 = %d %s, want 2025 welcome", a.state.CurrentYear, a.state.Screen)
+	}
+
+	out.Reset()
+	press(a, 'q', input.KeyUnknown)
+	if _, ok := a.current.(*goodbyeScreen); !ok || !strings.Contains(out.String(), "GOODBYE 2025") {
+		t.Fatalf("Q from the newest year = %T, want the 2025 goodbye screen drawn", a.current)
+	}
+	press(a, ' ', input.KeyUnknown)
+	if !a.done {
+		t.Error("a key on the goodbye screen didn't end the session")
+	}
+}
+
+func TestAppBackStaysOutOfRestrictedYear(t *testing.T) {
+	var out bytes.Buffer
+	access := validation.Access{Years: map[int]int{2025: 50}}
+	a := newTestApp(t, 10, access, strings.NewReader(""), &out)
+	if !a.startInOpenYear() || a.state.CurrentYear != 2024 {
+		t.Fatalf("start = %d, want 2024 for a caller below 2025's level", a.state.CurrentYear)
+	}
+	a.show()
+
+	press(a, '2', input.KeyUnknown)
+	if a.state.CurrentYear != 2024 {
+		t.Errorf("2 switched to %d below its level", a.state.CurrentYear)
+	}
+	press(a, 'q', input.KeyUnknown)
+	if a.state.Screen != navigation.ScreenExit || a.state.CurrentYear != 2024 {
+		t.Errorf("Q = %d %s, want to leave from 2024", a.state.CurrentYear, a.state.Screen)
+	}
+}
+
+func TestAppHotkeyLevel(t *testing.T) {
+	var out bytes.Buffer
+	a := newTestApp(t, 10, validation.Access{}, strings.NewReader(""), &out)
+	a.show()
+
+	out.Reset()
+	press(a, 'r', input.KeyUnknown)
+	if a.state.Screen != navigation.ScreenWelcome {
+		t.Errorf("R below the screen's level opened %s", a.state.Screen)
+	}
+	if !strings.Contains(out.String(), "That screen needs security level 20") {
+		t.Errorf("R below the screen's level drew %q, want the level notice", out.String())
+	}
+
+	a.user.SecurityLevel = 20
+	out.Reset()
+	press(a, 'r', input.KeyUnknown)
+	if a.state.Screen != navigation.ScreenCustom || a.state.Custom != "rules" || !strings.Contains(out.String(), "RULES") {
+		t.Errorf("R at the screen's level = %s %q, want the rules screen drawn", a.state.Screen, a.state.Custom)
+	}
+}
+
+func TestNotYetTicksUntilTimeout(t *testing.T) {
+	var out bytes.Buffer
+	in, w := io.Pipe() // No key ever comes
+	defer w.Close()
+	a := newTestApp(t, 10, validation.Access{}, in, &out)
+	a.state = a.navigator.OpenScreen(a.state, navigation.ScreenNotYet)
+	a.show()
+
+	s, ok := a.current.(*notYetScreen)
+	if !ok {
+		t.Fatalf("screen = %T, want the countdown", a.current)
+	}
+	s.Tick(a)
+	if a.done {
+		t.Fatal("the countdown quit before its timeout")
+	}
+
+	// Run out the countdown part way through the next tick
+	s.deadline = time.Now().Add(tickInterval / 2)
+	start := time.Now()
+	if _, _, err := a.readKey(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.done {
+		t.Error("readKey returned before the countdown timed out")
+	}
+	if elapsed := time.Since(start); elapsed > 3*tickInterval {
+		t.Errorf("the countdown took %v to time out", elapsed)
+	}
+}
+
+func TestAppRedrawsOnResize(t *testing.T) {
+	var out bytes.Buffer
+	in, w := io.Pipe()
+	defer w.Close()
+	a := newTestApp(t, 10, validation.Access{}, in, &out)
+	a.terminalSize = func() (int, int) {
+		go w.Write([]byte("z")) // Typed once the resize is under way
+		return 100, 30
+	}
+	a.show()
+
+	out.Reset()
+	a.inputHandler.Wake()
+	char, _, err := a.readKey()
+	if err != nil || char != 'z' {
+		t.Fatalf("readKey() = %q, %v, want 'z'", char, err)
+	}
+	if w, h := a.displayEngine.GetDimensions(); w != 100 || h != 30 || a.user.W != 100 || a.user.H != 30 {
+		t.Errorf("after resize the screen is %dx%d and the caller %dx%d, want 100x30", w, h, a.user.W, a.user.H)
+	}
+	if !strings.Contains(out.String(), "WELCOME 2025") {
+		t.Errorf("resize didn't redraw the screen: %q", out.String())
+	}
+}
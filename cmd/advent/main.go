@@ -3,15 +3,23 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // Unlock time zones must work on hosts without zoneinfo (Windows)
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/term"
 
 	"github.com/robbiew/advent/internal/art"
+	"github.com/robbiew/advent/internal/artfs"
 	"github.com/robbiew/advent/internal/bbs"
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/clock"
+	"github.com/robbiew/advent/internal/config"
 	"github.com/robbiew/advent/internal/display"
 	"github.com/robbiew/advent/internal/embedded"
 	"github.com/robbiew/advent/internal/input"
@@ -29,14 +37,18 @@ var (
 
 var (
 	// Command line flags
-	localMode    = flag.Bool("local", false, "run in local UTF-8 mode")
-	debugDate    = flag.String("debug-date", "", "override date (YYYY-MM-DD)")
-	disableDate  = flag.Bool("debug-disable-date", false, "disable date validation")
-	dropfilePath = flag.String("path", "", "path to door32.sys file")
-	logonMode    = flag.Bool("logon", false, "logon mode: show current day's door, then COMEBACK.ANS and exit")
-	showVersion  = flag.Bool("version", false, "show version information")
-	noIce        = flag.Bool("noice", false, "disable ICE mode control codes (for terminals that don't support them)")
-	noDetect     = flag.Bool("nodetect", false, "disable terminal size detection (use default 80x25)")
+	localMode     = flag.Bool("local", false, "run in local UTF-8 mode")
+	debugDate     = flag.String("debug-date", "", "simulate a date and time in the unlock time zone (YYYY-MM-DD or YYYY-MM-DDTHH:MM)")
+	disableDate   = flag.Bool("debug-disable-date", false, "disable date validation")
+	dropfilePath  = flag.String("path", "", "path to dropfile (door32.sys, DOOR.SYS, DORINFOx.DEF, CHAIN.TXT, PCBOARD.SYS or XTRN.DAT)")
+	logonMode     = flag.Bool("logon", false, "logon mode: show current day's door, then COMEBACK.ANS and exit")
+	showVersion   = flag.Bool("version", false, "show version information")
+	noIce         = flag.Bool("noice", false, "disable ICE mode control codes (for terminals that don't support them)")
+	noDetect      = flag.Bool("nodetect", false, "disable terminal size detection (use default 80x25)")
+	configPath    = flag.String("config", "", "path to config file (default: "+config.DefaultFileName+" next to the executable)")
+	artDir        = flag.String("artdir", "", "on-disk art directory that overrides the built-in art")
+	hashKeyYear   = flag.String("hashkey", "", "print the hash of a year's unlock key for the config or calendar.json: -hashkey YEAR KEY")
+	serveProtocol = flag.String("serve", "", "run a built-in server instead of a door: -serve telnet|ssh|web [addr] (default "+defaultTelnetAddr+" / "+defaultSSHAddr+" / "+defaultWebAddr+")")
 )
 
 func main() {
@@ -60,6 +72,17 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Hash an unlock key for the sysop and exit
+	if *hashKeyYear != "" {
+		hash, err := hashKey(*hashKeyYear, strings.Join(flag.Args(), " "))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "advent: -hashkey: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(hash)
+		os.Exit(0)
+	}
+
 	// Set log level - Default to ErrorLevel to hide info/debug messages from sysop console
 	logrus.SetLevel(logrus.ErrorLevel)
 	logrus.SetFormatter(&logrus.TextFormatter{
@@ -67,15 +90,53 @@ func main() {
 		FullTimestamp:   true,
 	})
 
+	// Load config file (optional unless -config is given), then let flags override it
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "advent: %v\n", err)
+		os.Exit(1)
+	}
+	if err := setupLogging(cfg.Log); err != nil {
+		fmt.Fprintf(os.Stderr, "advent: %v\n", err)
+		os.Exit(1)
+	}
+
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Flags parsed")
 
-	// Initialize components with embedded art filesystem
-	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Creating art manager")
-	artManager := art.NewManager(embedded.ArtFS, "art")
-	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Creating navigator")
-	navigator := navigation.NewNavigator(embedded.ArtFS, "art")
-	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Creating validator")
-	validator := validation.NewValidator(embedded.ArtFS, "art")
+	// Layer the sysop's art directory (if any) over the embedded art so all
+	// components resolve paths the same way
+	artFS := artfs.New(embedded.ArtFS, cfg.Art.Dir, "art")
+
+	// Share one calendar resolver so manifests are read once and all
+	// components agree on which file belongs to which day
+	resolver := calendar.NewResolver(artFS, "art")
+	resolver.SetUnlockRule(cfg.UnlockRule())
+	resolver.SetKeyHashes(cfg.KeyHashes())
+
+	// -debug-date runs the calendar on a simulated clock
+	clk := clock.System
+	if *debugDate != "" {
+		start, err := parseDebugDate(*debugDate, cfg.UnlockRule().Zone())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "advent: -debug-date: %v\n", err)
+			os.Exit(1)
+		}
+		logrus.WithField("time", start).Info("Simulating time from -debug-date")
+		clk = clock.Simulated(start)
+	}
+
+	// Built-in server mode: accept callers directly instead of running under a BBS
+	if *serveProtocol != "" {
+		if err := runServer(cfg, artFS, resolver, clk, *serveProtocol, flag.Arg(0)); err != nil {
+			fmt.Fprintf(os.Stderr, "advent: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Creating components")
+	artManager, navigator, validator := newCalendarComponents(artFS, resolver, clk)
+	validator.SetAccess(cfg.AccessLevels())
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Components created")
 
 	// Determine display mode
@@ -86,33 +147,13 @@ func main() {
 		displayMode = display.ModeCP437
 	}
 
-	// Initialize components with hard-coded sensible defaults
-	displayEngine := display.NewDisplayEngine(display.DisplayConfig{
-		Mode:   displayMode,
-		Width:  80, // Default, will be detected
-		Height: 25, // Default, will be detected
-		Theme:  "classic",
-		Scrolling: display.ScrollingConfig{
-			Enabled:           true,
-			Indicators:        false,
-			KeyboardShortcuts: true,
-		},
-		Columns: display.ColumnConfig{
-			Handle80ColumnIssue: true,
-			AutoDetectWidth:     true,
-		},
-		Performance: display.PerformanceConfig{
-			CacheEnabled: true,
-			CacheSizeMB:  50,
-			PreloadLines: 100,
-		},
-		NoIce: *noIce,
-	}, embedded.ArtFS)
+	// Initialize display with the configured settings (size is detected later)
+	displayEngine := display.NewDisplayEngine(cfg.DisplayConfig(displayMode, 80, 25), artFS)
 
 	// Initialize BBS connection from door32.sys if provided
 	var bbsConn *bbs.BBSConnection
 	if *dropfilePath != "" {
-		logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Creating BBS connection from door32.sys")
+		logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Creating BBS connection from dropfile")
 		var connErr error
 		bbsConn, connErr = bbs.NewBBSConnection(*dropfilePath)
 		logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: BBS connection created")
@@ -132,8 +173,8 @@ func main() {
 	if bbsConn != nil {
 		logrus.Info("BBS connection available - display output will be handled by modified display engine")
 	} // Initialize session manager
-	idleTimeout := 5 * time.Minute  // Hard-coded 5 minute idle timeout
-	maxTimeout := 120 * time.Minute // Hard-coded 2 hour max timeout
+	idleTimeout := cfg.Session.IdleTimeout.Std()
+	maxTimeout := cfg.Session.MaxTimeout.Std()
 
 	var sessionManager *session.Manager
 	sessionManager = session.NewManager(idleTimeout, maxTimeout,
@@ -148,12 +189,12 @@ func main() {
 
 	// Get user information
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Getting user info")
-	user := getUserInfo(*localMode)
+	user, knownUser := getUserInfo(*localMode)
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Got user info")
 
 	// Detect terminal size (prefer BBS connection query over term.GetSize)
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Detecting terminal size")
-	width, height := detectTerminalSize(bbsConn, *noDetect)
+	width, height := detectTerminalSize(bbsConn, cfg.Display.NoDetect)
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Terminal size detected")
 
 	// Update user struct with detected terminal size
@@ -167,26 +208,18 @@ func main() {
 		"height": height,
 	}).Info("Terminal size applied to user session")
 
-	displayEngine = display.NewDisplayEngine(display.DisplayConfig{
-		Mode:   displayMode,
-		Width:  width,
-		Height: height,
-		Theme:  "classic",
-		Scrolling: display.ScrollingConfig{
-			Enabled:           true,
-			Indicators:        false,
-			KeyboardShortcuts: true,
-		},
-		Columns: display.ColumnConfig{
-			Handle80ColumnIssue: true,
-			AutoDetectWidth:     true,
-		},
-		Performance: display.PerformanceConfig{
-			CacheEnabled: true,
-			CacheSizeMB:  50,
-			PreloadLines: 100,
-		},
-	}, embedded.ArtFS)
+	// Callers without ANSI get plain text with a "more" prompt; AVATAR
+	// callers get the art translated to AVATAR/0+ codes
+	switch user.Emulation {
+	case 0:
+		logrus.Info("Caller has no ANSI emulation - using ASCII display")
+		displayMode = display.ModeASCII
+	case 2:
+		logrus.Info("Caller uses AVATAR emulation - translating ANSI output")
+		displayMode = display.ModeAvatar
+	}
+
+	displayEngine = display.NewDisplayEngine(cfg.DisplayConfig(displayMode, width, height), artFS)
 
 	// Configure BBS output (different behavior on Windows vs Linux)
 	if bbsConn != nil {
@@ -194,16 +227,92 @@ func main() {
 		logrus.Info("Display engine configured for BBS output")
 	}
 
+	st := openStore(cfg)
+	d := &door{
+		artManager:     artManager,
+		navigator:      navigator,
+		validator:      validator,
+		displayEngine:  displayEngine,
+		inputHandler:   inputHandler,
+		sessionManager: sessionManager,
+		user:           user,
+		visits:         newVisits(st, user, knownUser, clk),
+		presence:       newPresence(st, user),
+		logon:          *logonMode,
+		archive:        cfg.ArchiveOffSeason(),
+		screens:        cfg.Screens,
+		started:        startTime,
+	}
+	d.sysop = newSysopMenu(d, cfg, st, clk)
+	d.run()
+}
+
+// newCalendarComponents creates the art manager, navigator and validator
+// for one session, all sharing the given resolver and clock
+func newCalendarComponents(artFS fs.FS, resolver *calendar.Resolver, clk clock.Clock) (*art.Manager, *navigation.Navigator, *validation.Validator) {
+	artManager := art.NewManager(artFS, "art")
+	navigator := navigation.NewNavigator(artFS, "art")
+	validator := validation.NewValidator(artFS, "art")
+
+	artManager.SetResolver(resolver)
+	navigator.SetResolver(resolver)
+	validator.SetResolver(resolver)
+	navigator.SetClock(clk)
+	validator.SetClock(clk)
+	return artManager, navigator, validator
+}
+
+// door holds the components of one caller's session
+type door struct {
+	artManager     *art.Manager
+	navigator      *navigation.Navigator
+	validator      *validation.Validator
+	displayEngine  *display.DisplayEngine
+	inputHandler   *input.InputHandler
+	sessionManager *session.Manager
+	user           display.User
+	visits         *visits               // Nil when visits aren't remembered
+	presence       *presence             // Nil when user data is unavailable
+	sysop          *sysopMenu            // Nil unless the caller's security level opens it
+	logon          bool                  // Show the current day's door and exit (-logon)
+	archive        bool                  // Browse past years outside December instead of exiting
+	screens        []config.ScreenConfig // The sysop's own screens
+	started        time.Time             // For startup timing logs
+
+	// terminalSize reports the caller's terminal size after the input
+	// handler is woken for a resize; nil when the size can't change (dropfiles)
+	terminalSize func() (width, height int)
+}
+
+// run checks the terminal and calendar, then runs logon mode or the main loop
+func (d *door) run() {
+	startTime := d.started
+	displayEngine := d.displayEngine
+	inputHandler := d.inputHandler
+	navigator := d.navigator
+	validator := d.validator
+	user := d.user
+
 	// Validate terminal size
-	if err := validator.ValidateTerminalSize(width, height); err != nil {
+	if err := validator.ValidateTerminalSize(user.W, user.H); err != nil {
 		logrus.WithError(err).Warn("Terminal size validation failed - continuing anyway")
 	}
 
-	// Validate ANSI emulation
+	// Validate emulation
 	if err := validator.ValidateEmulation(user.Emulation); err != nil {
-		logrus.WithError(err).Warn("ANSI emulation validation failed - continuing anyway")
+		logrus.WithError(err).Warn("Emulation validation failed - continuing anyway")
 	}
 
+	// ASCII mode pages long screens; wait for a key at each "more" prompt
+	displayEngine.SetMoreFunc(func() bool {
+		char, key, err := inputHandler.ReadKey()
+		if err != nil {
+			return false
+		}
+		d.sessionManager.ResetIdleTimer()
+		return char != 'q' && char != 'Q' && key != input.KeyEsc
+	})
+
 	// Get initial navigation state
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: Getting initial state")
 	initialState, err := navigator.GetInitialState()
@@ -213,25 +322,23 @@ func main() {
 		return
 	}
 
-	// Apply debug overrides
-	if *disableDate || *debugDate != "" {
-		if *disableDate {
-			logrus.Info("Date validation disabled by debug flag")
-			navigator.SetDisableDateCheck(true)
-			// Recalculate MaxDay with date checking disabled
-			initialState, err = navigator.GetInitialState()
-			if err != nil {
-				logrus.WithError(err).Error("Failed to recalculate initial state after disabling date check")
-				return
-			}
-		}
-		if *debugDate != "" {
-			logrus.Info("Date validation skipped due to debug-date override")
+	// Apply debug overrides (-debug-date needs none: it sets the clock)
+	if *disableDate {
+		logrus.Info("Date validation disabled by debug flag")
+		navigator.SetDisableDateCheck(true)
+		// Recalculate MaxDay with date checking disabled
+		initialState, err = navigator.GetInitialState()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to recalculate initial state after disabling date check")
+			return
 		}
 	} else {
 		if err := validator.ValidateDate(); err != nil {
-			displayNotYet(displayEngine, artManager, initialState.CurrentYear, user, inputHandler)
-			return
+			if !d.archive || d.logon || !hasOpenYear(navigator, initialState) {
+				d.notYet(initialState)
+				return
+			}
+			logrus.WithField("years", initialState.AvailableYears).Info("Off season - browsing the archive")
 		}
 	}
 
@@ -241,23 +348,33 @@ func main() {
 		return
 	}
 
-	// Apply date override if specified
-	if *debugDate != "" {
-		if err := applyDateOverride(&initialState, *debugDate); err != nil {
-			logrus.WithError(err).Error("Failed to apply date override")
-			return
-		}
-	}
+	d.visits.start()
 
 	// Handle logon mode - skip welcome screen and go directly to current day's door
-	if *logonMode {
-		runLogonMode(displayEngine, artManager, inputHandler, sessionManager, initialState, user, validator)
+	if d.logon {
+		runLogonMode(displayEngine, d.artManager, navigator, inputHandler, d.sessionManager, initialState, user, validator)
+		return
+	}
+
+	// Resume where the caller left off (logon mode above always shows today's
+	// door), unless the year is now closed to them; start in the newest year
+	// that's open to them when the current one isn't
+	app := newApp(d, initialState)
+	resumed := initialState
+	d.visits.resume(navigator, &resumed)
+	if app.yearOpen(resumed.CurrentYear) {
+		app.state = resumed
+	}
+	if !app.startInOpenYear() {
+		logrus.WithField("level", user.SecurityLevel).Warn("No year is open to the caller's security level")
+		displayEngine.DisplayText([]string{"", "  Sorry, your security level doesn't open any year of the calendar."})
+		time.Sleep(noYearPause)
 		return
 	}
 
 	// Start session manager
-	sessionManager.Start()
-	defer sessionManager.Stop()
+	d.sessionManager.Start()
+	defer d.sessionManager.Stop()
 
 	// Open input handler
 	logrus.WithField("elapsed", time.Since(startTime)).Info("STARTUP: About to open input handler")
@@ -280,49 +397,139 @@ func main() {
 	}()
 
 	// Main application loop
-	runMainLoop(displayEngine, artManager, navigator, inputHandler, sessionManager, initialState, user)
+	defer d.presence.leave()
+	app.Run()
+	cleanup(displayEngine, inputHandler, d.sessionManager)
+}
+
+// noYearPause is how long a caller no year is open to sees why before the
+// door exits
+const noYearPause = 3 * time.Second
+
+// notYet shows the countdown to the coming season until a key is pressed or
+// it times out
+func (d *door) notYet(state navigation.State) {
+	app := newApp(d, d.navigator.OpenScreen(state, navigation.ScreenNotYet))
+	if err := d.inputHandler.Open(); err != nil {
+		// Without input, just pause
+		app.show()
+		time.Sleep(notYetTimeout)
+		return
+	}
+	defer d.inputHandler.Close()
+	app.Run()
+}
+
+// hashKey returns the hash of a year's unlock key
+func hashKey(yearArg, key string) (string, error) {
+	year, err := strconv.Atoi(yearArg)
+	if err != nil || !calendar.ValidYear(year) {
+		return "", fmt.Errorf("%q is not a year (usage: -hashkey YEAR KEY)", yearArg)
+	}
+	if strings.TrimSpace(key) == "" {
+		return "", fmt.Errorf("no key given (usage: -hashkey YEAR KEY)")
+	}
+	return calendar.HashKey(year, key)
+}
+
+// loadConfig reads the config file and applies command line overrides
+func loadConfig() (*config.Config, error) {
+	path := *configPath
+	required := path != ""
+	if !required {
+		path = config.DefaultPath()
+	}
+
+	cfg, err := config.Load(path, required)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flags given on the command line win over values from the file
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "noice":
+			cfg.Display.NoIce = *noIce
+		case "nodetect":
+			cfg.Display.NoDetect = *noDetect
+		case "artdir":
+			cfg.Art.Dir = *artDir
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// setupLogging applies the configured log level and destination
+func setupLogging(logCfg config.LogConfig) error {
+	level, err := logrus.ParseLevel(logCfg.Level)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(level)
+
+	if logCfg.File != "" {
+		file, err := os.OpenFile(logCfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		logrus.SetOutput(file)
+	}
+	return nil
 }
 
-func getUserInfo(localMode bool) display.User {
+// getUserInfo returns the caller's details and whether they identify a real
+// caller (false for the fallback user shared by everyone)
+func getUserInfo(localMode bool) (display.User, bool) {
 	if localMode {
 		logrus.Info("Running in local mode")
 		return display.User{
-			Alias:     "SysOp",
-			TimeLeft:  120 * time.Minute,
-			Emulation: 1,
-			NodeNum:   1,
-			H:         25,
-			W:         80,
-			ModalH:    25,
-			ModalW:    80,
-		}
+			Alias:         "SysOp",
+			BBSName:       "local",
+			SecurityLevel: 255,
+			TimeLeft:      120 * time.Minute,
+			Emulation:     1,
+			NodeNum:       1,
+			H:             25,
+			W:             80,
+			ModalH:        25,
+			ModalW:        80,
+		}, true
 	}
 
-	// BBS mode - parse door32.sys if available
+	// BBS mode - parse the dropfile if available
 	logrus.Info("Running in BBS mode")
 
 	if *dropfilePath != "" {
-		door32Info, err := bbs.ParseDoor32(*dropfilePath)
+		door32Info, err := bbs.ParseDropfile(*dropfilePath)
 		if err != nil {
-			logrus.WithError(err).Warn("Failed to parse door32.sys, using defaults")
+			logrus.WithError(err).Warn("Failed to parse dropfile, using defaults")
 		} else {
 			logrus.WithFields(logrus.Fields{
 				"alias":     door32Info.Alias,
 				"timeLeft":  door32Info.TimeLeft,
 				"emulation": door32Info.Emulation,
 				"node":      door32Info.NodeNumber,
-			}).Info("Parsed user info from door32.sys")
+				"bbs":       door32Info.BBSName,
+				"record":    door32Info.UserRecord,
+			}).Info("Parsed user info from dropfile")
 
 			return display.User{
-				Alias:     door32Info.Alias,
-				TimeLeft:  time.Duration(door32Info.TimeLeft) * time.Minute,
-				Emulation: door32Info.Emulation,
-				NodeNum:   door32Info.NodeNumber,
-				H:         25,
-				W:         80,
-				ModalH:    25,
-				ModalW:    80,
-			}
+				Alias:         door32Info.Alias,
+				BBSName:       door32Info.BBSName,
+				UserRecord:    door32Info.UserRecord,
+				SecurityLevel: door32Info.SecurityLevel,
+				TimeLeft:      time.Duration(door32Info.TimeLeft) * time.Minute,
+				Emulation:     door32Info.Emulation,
+				NodeNum:       door32Info.NodeNumber,
+				H:             25,
+				W:             80,
+				ModalH:        25,
+				ModalW:        80,
+			}, true
 		}
 	}
 
@@ -336,7 +543,7 @@ func getUserInfo(localMode bool) display.User {
 		W:         80,
 		ModalH:    25,
 		ModalW:    80,
-	}
+	}, false
 }
 
 func detectTerminalSize(bbsConn *bbs.BBSConnection, noDetect bool) (width, height int) {
@@ -406,457 +613,137 @@ func detectTerminalSize(bbsConn *bbs.BBSConnection, noDetect bool) (width, heigh
 	return 80, 25
 }
 
-func applyDateOverride(state *navigation.State, dateStr string) error {
-	// Parse date string in YYYY-MM-DD format
-	parsedDate, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		return fmt.Errorf("invalid date format (expected YYYY-MM-DD): %w", err)
-	}
-
-	// Extract the day from the date
-	day := parsedDate.Day()
+// debugDateLayouts are the forms -debug-date accepts, most specific first
+var debugDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
 
-	// Validate day is within advent calendar range (1-25)
-	if day < 1 || day > 25 {
-		return fmt.Errorf("day %d is out of advent calendar range (1-25)", day)
+// parseDebugDate reads a -debug-date value. Times without a zone are in loc
+// (the unlock time zone) and a date alone means midnight at its start.
+func parseDebugDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range debugDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
 	}
-
-	// Update state to simulate it being this date
-	// CurrentDay starts at 1, but MaxDay is set to the debug day
-	// This allows navigation from day 1 up to (and including) the debug day
-	state.CurrentDay = 1
-	state.MaxDay = day
-
-	logrus.WithFields(logrus.Fields{
-		"debugDate":  dateStr,
-		"currentDay": state.CurrentDay,
-		"maxDay":     state.MaxDay,
-	}).Info("Applied debug date override - simulating current date")
-
-	return nil
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or YYYY-MM-DDTHH:MM)", value)
 }
 
-func displayNotYet(displayEngine *display.DisplayEngine, artManager *art.Manager, year int, user display.User, inputHandler *input.InputHandler) {
-	// Display "not yet" screen
-	notYetPath := artManager.GetPath(year, 0, "notyet")
-	if notYetPath != "" {
-		displayEngine.Display(notYetPath, user)
+// hasOpenYear reports whether any year has a day open to browse
+func hasOpenYear(navigator *navigation.Navigator, state navigation.State) bool {
+	for _, year := range state.AvailableYears {
+		if navigator.MaxDay(year) > 0 {
+			return true
+		}
 	}
+	return false
+}
 
-	// Wait for key press or 10 seconds, whichever comes first (no visible prompt)
-	if inputHandler != nil {
-		// Open input handler temporarily if not already open
-		wasOpen := true
-		if err := inputHandler.Open(); err != nil {
-			// If we can't open input handler, fall back to 10 second wait
-			time.Sleep(10 * time.Second)
-			return
-		}
-		defer func() {
-			if !wasOpen {
-				inputHandler.Close()
-			}
-		}()
-
-		// Create a channel for key press
-		keyPressed := make(chan bool, 1)
-
-		// Start goroutine to wait for key press
-		go func() {
-			inputHandler.ReadKey()
-			keyPressed <- true
-		}()
-
-		// Wait for either key press or timeout
-		select {
-		case <-keyPressed:
-			logrus.Info("NOTYET: key pressed, exiting")
-		case <-time.After(10 * time.Second):
-			logrus.Info("NOTYET: timeout reached, exiting")
+// teaserNotice returns the footer for a year that hasn't opened yet: the
+// keys of the years callers can browse meanwhile
+func teaserNotice(navigator *navigation.Navigator, state navigation.State) string {
+	var keys []string
+	for i, year := range state.AvailableYears {
+		if i < 9 && navigator.MaxDay(year) > 0 {
+			keys = append(keys, fmt.Sprintf("%d=%d", i+1, year))
 		}
-	} else {
-		// Fallback: 10 second pause
-		time.Sleep(10 * time.Second)
 	}
+	if len(keys) == 0 {
+		return ""
+	}
+	if len(state.AvailableYears) > 9 {
+		keys = append(keys, "Y=more")
+	}
+	return "Coming soon! Browse past years: " + strings.Join(keys, "  ") + "  Q=quit"
 }
 
-func runMainLoop(displayEngine *display.DisplayEngine, artManager *art.Manager,
-	navigator *navigation.Navigator, inputHandler *input.InputHandler,
-	sessionManager *session.Manager, state navigation.State, user display.User) {
+// countdownNotice returns the countdown line for the not-yet screen, such as
+// "Advent opens in 3 days, 4 hours - Dec 1 at 18:00 EST"
+func countdownNotice(navigator *navigation.Navigator, start time.Time) string {
+	left := start.Sub(navigator.Now())
+	if left <= 0 {
+		return "Advent is open - call back in to see day 1!"
+	}
+	return fmt.Sprintf("Advent opens in %s - %s", calendar.Countdown(left), start.Format("Jan 2 at 15:04 MST"))
+}
 
-	loopStart := time.Now()
-	currentState := state
-	var currentArtPath string
+// screenAllowed reports whether the caller's security level opens a screen,
+// and says what it needs along the bottom of the current screen if not
+func screenAllowed(displayEngine *display.DisplayEngine, validator *validation.Validator,
+	user display.User, screen navigation.ScreenType) bool {
+	level := validator.ScreenLevel(screen.String())
+	if user.SecurityLevel >= level {
+		return true
+	}
+	logrus.WithFields(logrus.Fields{"screen": screen, "required": level}).Info("Screen restricted by security level")
+	displayEngine.UpdateNotice(fmt.Sprintf("That screen needs security level %d", level))
+	return false
+}
 
-	// For scrolling screens
-	var infoLoaded, membersLoaded bool
-	var infoLines, membersLines []string
-	var infoScrollPos, membersScrollPos int
+// promptKey asks for the key that unlocks a year along the bottom of the
+// current screen and reports whether the caller entered it. Esc cancels.
+func promptKey(displayEngine *display.DisplayEngine, inputHandler *input.InputHandler,
+	sessionManager *session.Manager, validator *validation.Validator, year int) bool {
+	key, ok := readLine(displayEngine, inputHandler, sessionManager,
+		fmt.Sprintf("%d is locked - enter its key (Esc to cancel): ", year))
+	if !ok {
+		return false
+	}
+	if validator.ValidateKey(year, key) {
+		logrus.WithField("year", year).Info("Year unlocked with key")
+		return true
+	}
+	logrus.WithField("year", year).Info("Wrong key entered")
+	displayEngine.UpdateNotice(fmt.Sprintf("That's not the key for %d", year))
+	return false
+}
 
-	logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Starting first iteration")
+// maxLineLength caps what readLine accepts
+const maxLineLength = 32
 
+// readLine reads a line typed along the bottom of the current screen after
+// prompt. It reports false when the caller presses Esc or hangs up.
+func readLine(displayEngine *display.DisplayEngine, inputHandler *input.InputHandler,
+	sessionManager *session.Manager, prompt string) (string, bool) {
+	var typed []rune
+	displayEngine.UpdateNotice(prompt)
 	for {
-		// Display current screen only if the art path changed
-		var artPath string
-		switch currentState.Screen {
-		case navigation.ScreenWelcome:
-			logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Getting welcome art path")
-			artPath = artManager.GetPath(currentState.CurrentYear, 0, "welcome")
-			logrus.WithField("elapsed", time.Since(loopStart)).WithField("path", artPath).Info("MAINLOOP: Got welcome art path")
-		case navigation.ScreenDay:
-			artPath = artManager.GetPath(currentState.CurrentYear, currentState.CurrentDay, "day")
-		case navigation.ScreenComeback:
-			artPath = artManager.GetPath(currentState.CurrentYear, 0, "comeback")
-		case navigation.ScreenInfo:
-			artPath = artManager.GetPath(currentState.CurrentYear, 0, "info")
-		case navigation.ScreenMembers:
-			artPath = artManager.GetPath(currentState.CurrentYear, 0, "members")
-		}
-
-		// Only display if art path changed
-		if artPath != "" && artPath != currentArtPath {
-			logrus.WithField("elapsed", time.Since(loopStart)).WithField("path", artPath).Info("MAINLOOP: About to display art")
-			logrus.WithFields(logrus.Fields{
-				"artPath":        artPath,
-				"currentArtPath": currentArtPath,
-				"screen":         currentState.Screen,
-				"day":            currentState.CurrentDay,
-			}).Debug("Displaying art")
-
-			switch currentState.Screen {
-			case navigation.ScreenInfo:
-				if !infoLoaded {
-					lines, err := displayEngine.LoadAnsiLines(artPath)
-					if err == nil {
-						infoLines = lines
-						infoLoaded = true
-						infoScrollPos = 0 // Always start at top
-					} else {
-						infoLines = []string{fmt.Sprintf("[Unable to load %s]", artPath)}
-						infoLoaded = true
-						infoScrollPos = 0
-					}
-				}
-				// Always use scrolling logic for INFOFILE.ANS
-				displayEngine.SetScrollState(infoScrollPos, len(infoLines))
-				displayEngine.RenderScrollable(infoLines, infoScrollPos)
-				currentArtPath = artPath
-			case navigation.ScreenMembers:
-				if !membersLoaded {
-					lines, err := displayEngine.LoadAnsiLines(artPath)
-					if err == nil {
-						membersLines = lines
-						membersLoaded = true
-						membersScrollPos = 0 // Always start at top
-					} else {
-						membersLines = []string{fmt.Sprintf("[Unable to load %s]", artPath)}
-						membersLoaded = true
-						membersScrollPos = 0
-					}
-				}
-				// Always use scrolling logic for MEMBERS.ANS
-				displayEngine.SetScrollState(membersScrollPos, len(membersLines))
-				displayEngine.RenderScrollable(membersLines, membersScrollPos)
-				currentArtPath = artPath
-			default:
-				logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Calling displayEngine.Display()")
-				if err := displayEngine.Display(artPath, user); err != nil {
-					logrus.WithError(err).Error("Failed to display art")
-				}
-				logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: displayEngine.Display() returned")
-				currentArtPath = artPath
-			}
-		}
-
-		// Get user input
-		// CRITICAL: Give Mystic BBS time to transmit buffered output to user's terminal before blocking on input
-		// Windows 7 + Mystic BBS needs significant time to flush output buffers
-		logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Waiting 500ms before reading input (BBS output stabilization)")
-		time.Sleep(500 * time.Millisecond)
-
-		logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Now reading user input")
 		char, key, err := inputHandler.ReadKey()
-		logrus.WithField("elapsed", time.Since(loopStart)).Info("MAINLOOP: Got user input")
 		if err != nil {
-			logrus.WithError(err).Error("Failed to read input")
-			continue
-		}
-
-		// Handle scrolling for Info/Members screens
-		// Reserve last row for menu bar (user.H - 1 is usable height)
-		if currentState.Screen == navigation.ScreenInfo {
-			// Get the scroll state to determine visible lines
-			scrollState := displayEngine.GetScrollState()
-			visibleLines := scrollState.VisibleLines
-
-			if key == input.KeyArrowUp && infoScrollPos > 0 {
-				infoScrollPos--
-				logrus.WithField("infoScrollPos", infoScrollPos).Debug("Scrolling info up")
-				displayEngine.RenderScrollableContentOnly(infoLines, infoScrollPos)
-				continue
-			} else if key == input.KeyArrowDown && infoScrollPos < len(infoLines)-visibleLines {
-				infoScrollPos++
-				logrus.WithField("infoScrollPos", infoScrollPos).Debug("Scrolling info down")
-				displayEngine.RenderScrollableContentOnly(infoLines, infoScrollPos)
-				continue
-			} else if key == input.KeyArrowUp || key == input.KeyArrowDown {
-				logrus.WithFields(logrus.Fields{
-					"key":          key,
-					"scrollPos":    infoScrollPos,
-					"totalLines":   len(infoLines),
-					"visibleLines": visibleLines,
-					"maxScrollPos": len(infoLines) - visibleLines,
-				}).Debug("Arrow key pressed but bounds check failed")
-			}
-		} else if currentState.Screen == navigation.ScreenMembers {
-			// Get the scroll state to determine visible lines
-			scrollState := displayEngine.GetScrollState()
-			visibleLines := scrollState.VisibleLines
-
-			if key == input.KeyArrowUp && membersScrollPos > 0 {
-				membersScrollPos--
-				logrus.WithField("membersScrollPos", membersScrollPos).Debug("Scrolling members up")
-				displayEngine.RenderScrollableContentOnly(membersLines, membersScrollPos)
-				continue
-			} else if key == input.KeyArrowDown && membersScrollPos < len(membersLines)-visibleLines {
-				membersScrollPos++
-				logrus.WithField("membersScrollPos", membersScrollPos).Debug("Scrolling members down")
-				displayEngine.RenderScrollableContentOnly(membersLines, membersScrollPos)
-				continue
-			} else if key == input.KeyArrowUp || key == input.KeyArrowDown {
-				logrus.WithFields(logrus.Fields{
-					"key":          key,
-					"scrollPos":    membersScrollPos,
-					"totalLines":   len(membersLines),
-					"visibleLines": visibleLines,
-					"maxScrollPos": len(membersLines) - visibleLines,
-				}).Debug("Arrow key pressed but bounds check failed")
-			}
+			return "", false
 		}
-
-		// Reset idle timer
 		sessionManager.ResetIdleTimer()
 
-		// Handle quit/back navigation
-		if char == 'q' || char == 'Q' || key == input.KeyEsc {
-			// Get the latest year from available years
-			latestYear := currentState.AvailableYears[len(currentState.AvailableYears)-1]
-
-			// Exit on Q/ESC from WELCOME or COMEBACK in the latest year
-			if (currentState.Screen == navigation.ScreenWelcome || currentState.Screen == navigation.ScreenComeback) && currentState.CurrentYear == latestYear {
-				// Only exit if we're on the welcome screen of the latest year (2025)
-				logrus.Info("User requested exit from latest year's welcome screen")
-				exitPath := artManager.GetPath(currentState.CurrentYear, 0, "goodbye")
-				if exitPath != "" {
-					displayEngine.Display(exitPath, user)
-
-					// Wait for key press or 10 seconds, whichever comes first (no visible prompt)
-					keyPressed := make(chan bool, 1)
-
-					// Start goroutine to wait for key press
-					go func() {
-						inputHandler.ReadKey()
-						keyPressed <- true
-					}()
-
-					// Wait for either key press or timeout
-					select {
-					case <-keyPressed:
-						logrus.Info("GOODBYE: key pressed, exiting")
-					case <-time.After(10 * time.Second):
-						logrus.Info("GOODBYE: timeout reached, exiting")
-					}
-				}
-				break
-			} else if currentState.Screen == navigation.ScreenInfo || currentState.Screen == navigation.ScreenMembers {
-				// Return to welcome screen from Info/Members
-				currentState.Screen = navigation.ScreenWelcome
-				infoScrollPos = 0
-				membersScrollPos = 0
-				// Reset loaded flags to ensure proper reloading next time
-				infoLoaded = false
-				membersLoaded = false
-				continue
-			} else {
-				// Go back to welcome screen of the latest year
-				logrus.Info("User requested return to welcome screen")
-
-				// Get the latest year from available years
-				latestYear := currentState.AvailableYears[len(currentState.AvailableYears)-1]
-
-				// Only change the year if we're not already in the latest year
-				if currentState.CurrentYear != latestYear {
-					logrus.WithFields(logrus.Fields{
-						"previousYear": currentState.CurrentYear,
-						"latestYear":   latestYear,
-					}).Info("Returning to latest year's welcome screen")
-					currentState.CurrentYear = latestYear
-				}
-
-				currentState.Screen = navigation.ScreenWelcome
-				continue
-			}
-		}
-
-		// Handle year selection from welcome/comeback screen with numeric keys
-		if (currentState.Screen == navigation.ScreenWelcome || currentState.Screen == navigation.ScreenComeback) && char >= '1' && char <= '9' {
-			yearIndex := int(char - '0')
-			newState, newArtPath, err := navigator.SelectYearByIndex(yearIndex, currentState)
-			if err != nil {
-				logrus.WithFields(logrus.Fields{
-					"index": yearIndex,
-					"char":  string(char),
-				}).Debug("Invalid year selection")
-				// Invalid selection, just ignore
-			} else {
-				logrus.WithFields(logrus.Fields{
-					"index":        yearIndex,
-					"selectedYear": newState.CurrentYear,
-					"artPath":      newArtPath,
-				}).Info("Year selected")
-				currentState = newState
-			}
-			continue
-		}
-
-		// Handle Info/Members menu keys from welcome/comeback screen
-		if (currentState.Screen == navigation.ScreenWelcome || currentState.Screen == navigation.ScreenComeback) && (char == 'i' || char == 'I') {
-			currentState.Screen = navigation.ScreenInfo
-			infoScrollPos = 0
-			// Force reload of INFOFILE.ANS to ensure proper handling
-			infoLoaded = false
-			continue
-		}
-		if (currentState.Screen == navigation.ScreenWelcome || currentState.Screen == navigation.ScreenComeback) && (char == 'm' || char == 'M') {
-			currentState.Screen = navigation.ScreenMembers
-			membersScrollPos = 0
-			// Force reload of MEMBERS.ANS to ensure proper handling
-			membersLoaded = false
-			continue
-		}
-
-		// Handle scrolling keys first (if content is scrollable)
-		scrollHandled := false
-		if currentState.Screen == navigation.ScreenDay {
-			scrollState := displayEngine.GetScrollState()
-			switch key {
-			case input.KeyArrowUp:
-				if scrollState.CanScrollUp {
-					displayEngine.ScrollUp()
-					scrollHandled = true
-				}
-			case input.KeyArrowDown:
-				if scrollState.CanScrollDown {
-					displayEngine.ScrollDown()
-					scrollHandled = true
-				}
-			}
-		}
-
-		// If scroll was handled, skip navigation
-		if scrollHandled {
-			continue
-		}
-
-		// Handle navigation
-		var direction navigation.Direction
-
-		// Handle RETURN key on WELCOME/COMEBACK screen to navigate to current day (same as arrow right)
-		if (currentState.Screen == navigation.ScreenWelcome || currentState.Screen == navigation.ScreenComeback) && (char == '\r' || char == '\n') {
-			direction = navigation.DirRight
-			logrus.Info("RETURN pressed on WELCOME - navigating to current day")
-		}
-
-		switch key {
-		case input.KeyArrowRight:
-			direction = navigation.DirRight
-			logrus.WithField("direction", "right").Debug("Right arrow pressed")
-		case input.KeyArrowLeft:
-			direction = navigation.DirLeft
-			logrus.WithField("direction", "left").Debug("Left arrow pressed")
-		case input.KeyPageUp:
-			direction = navigation.DirPageUp
-		case input.KeyPageDown:
-			direction = navigation.DirPageDown
-		case input.KeyHome:
-			direction = navigation.DirHome
-		case input.KeyEnd:
-			direction = navigation.DirEnd
-		}
-
-		if direction != 0 {
-			logrus.WithFields(logrus.Fields{
-				"direction":     direction,
-				"currentDay":    currentState.CurrentDay,
-				"currentScreen": currentState.Screen,
-			}).Debug("Attempting navigation")
-
-			newState, newArtPath, err := navigator.Navigate(direction, currentState)
-			if err != nil {
-				logrus.WithError(err).Error("Navigation error")
-				continue
-			}
-
-			logrus.WithFields(logrus.Fields{
-				"newDay":    newState.CurrentDay,
-				"newScreen": newState.Screen,
-				"artPath":   newArtPath,
-			}).Debug("Navigation result")
-
-			currentState = newState
-			if newArtPath != "" && newArtPath != artPath {
-				// Art path changed, will be displayed in next iteration
+		switch {
+		case key == input.KeyEsc:
+			displayEngine.UpdateNotice("")
+			return "", false
+		case key == input.KeyEnter:
+			return string(typed), true
+		case key == input.KeyBackspace:
+			if len(typed) > 0 {
+				typed = typed[:len(typed)-1]
 			}
+		case key == input.KeySpace && len(typed) < maxLineLength:
+			typed = append(typed, ' ')
+		case char >= ' ' && char <= '~' && len(typed) < maxLineLength:
+			typed = append(typed, char)
 		}
+		displayEngine.UpdateNotice(prompt + string(typed))
 	}
-
-	cleanup(displayEngine, inputHandler, sessionManager)
 }
 
-func runLogonMode(displayEngine *display.DisplayEngine, artManager *art.Manager, inputHandler *input.InputHandler, sessionManager *session.Manager, state navigation.State, user display.User, validator *validation.Validator) {
-
-	// Check if it's December (unless date validation is disabled)
-	if !*disableDate {
-		if err := validator.ValidateDate(); err != nil {
-			// Not December - show NOTYET.ANS with 10-second timeout
-			notYetPath := artManager.GetPath(state.CurrentYear, 0, "notyet")
-			if notYetPath != "" {
-				displayEngine.Display(notYetPath, user)
-			}
-
-			// Wait for key press or 10 seconds, whichever comes first (no visible prompt)
-			if inputHandler != nil {
-				// Open input handler temporarily if not already open
-				if err := inputHandler.Open(); err == nil {
-					defer inputHandler.Close()
-
-					// Create a channel for key press
-					keyPressed := make(chan bool, 1)
-
-					// Start goroutine to wait for key press
-					go func() {
-						inputHandler.ReadKey()
-						keyPressed <- true
-					}()
-
-					// Wait for either key press or timeout
-					select {
-					case <-keyPressed:
-						logrus.Info("NOTYET: key pressed, exiting")
-					case <-time.After(10 * time.Second):
-						logrus.Info("NOTYET: timeout reached, exiting")
-					}
-				}
-			}
-			return
-		}
-	}
+func runLogonMode(displayEngine *display.DisplayEngine, artManager *art.Manager, navigator *navigation.Navigator, inputHandler *input.InputHandler,
+	sessionManager *session.Manager, state navigation.State, user display.User, validator *validation.Validator) {
 
-	// It's December - get current day
-	now := time.Now()
-	currentDay := now.Day()
-	if currentDay > 25 {
-		currentDay = 25
+	// It's the season - show the newest day open under the unlock rule
+	currentDay := state.MaxDay
+	if currentDay < 1 {
+		currentDay = 1
 	}
 
 	// Start session manager
@@ -898,7 +785,7 @@ func runLogonMode(displayEngine *display.DisplayEngine, artManager *art.Manager,
 	displayEngine.DisableBlinkMode() // Re-enable ICE mode
 	displayEngine.ShowCursor()
 	displayEngine.ClearScreen()
-	fmt.Print("\033[0m") // Reset colors
+	displayEngine.ResetColors()
 }
 
 func cleanup(displayEngine *display.DisplayEngine, inputHandler *input.InputHandler, sessionManager *session.Manager) {
@@ -906,6 +793,6 @@ func cleanup(displayEngine *display.DisplayEngine, inputHandler *input.InputHand
 	inputHandler.Close()
 	displayEngine.DisableBlinkMode() // Re-enable ICE mode
 	displayEngine.ShowCursor()
-	displayEngine.ClearScreen() // ClearScreen already flushes
-	fmt.Print("\033[0m")        // Reset colors
+	displayEngine.ClearScreen()
+	displayEngine.ResetColors()
 }
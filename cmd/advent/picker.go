@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/navigation"
+)
+
+// calendarHelp is the calendar grid's notice while nothing is being typed
+const calendarHelp = "Arrows move - Enter opens - type a day number - Q back"
+
+// maxDayDigits caps the day number typed on the calendar grid
+const maxDayDigits = 2
+
+// dayPicker handles keys on the calendar grid: arrows move the cursor, and
+// Enter opens the day under it or the day number typed
+type dayPicker struct {
+	navigator     *navigation.Navigator
+	displayEngine *display.DisplayEngine
+	typed         string // Day number typed so far
+}
+
+// notice returns what the grid shows along the bottom
+func (p *dayPicker) notice() string {
+	if p.typed != "" {
+		return "Open day: " + p.typed
+	}
+	return calendarHelp
+}
+
+// handle applies a key pressed on the grid to state
+func (p *dayPicker) handle(char rune, key input.Key, state navigation.State) navigation.State {
+	switch {
+	case key == input.KeyEsc || char == 'q' || char == 'Q':
+		if p.typed != "" {
+			p.typed = ""
+			p.displayEngine.UpdateNotice(p.notice())
+			return state
+		}
+		return p.navigator.Back(state, nil)
+	case char >= '0' && char <= '9':
+		if len(p.typed) < maxDayDigits {
+			p.typed += string(char)
+		}
+		p.displayEngine.UpdateNotice(p.notice())
+		return state
+	case key == input.KeyBackspace:
+		if p.typed != "" {
+			p.typed = p.typed[:len(p.typed)-1]
+			p.displayEngine.UpdateNotice(p.notice())
+		}
+		return state
+	case key == input.KeyEnter:
+		day := state.Cursor
+		if p.typed != "" {
+			day, _ = strconv.Atoi(p.typed)
+			p.typed = ""
+		}
+		if day < 1 || day > calendar.LastDay {
+			p.displayEngine.UpdateNotice(fmt.Sprintf("There is no day %d", day))
+			return state
+		}
+		newState, _, err := p.navigator.OpenDay(state, day)
+		if err != nil {
+			logrus.WithError(err).Debug("Day picked on the calendar is not open")
+			p.displayEngine.UpdateNotice(fmt.Sprintf("Not yet - %v", err))
+			return state
+		}
+		logrus.WithField("day", day).Info("Day picked on the calendar")
+		return newState
+	}
+
+	direction := cursorDirection(char, key)
+	if direction == navigation.DirNone {
+		return state
+	}
+	newState, _, err := p.navigator.Navigate(direction, state)
+	if err != nil {
+		logrus.WithError(err).Error("Navigation error")
+		return state
+	}
+	return newState
+}
+
+// cursorDirection returns where a key moves the cursor on generated screens
+// (< and > for terminals without arrow keys), or DirNone
+func cursorDirection(char rune, key input.Key) navigation.Direction {
+	switch {
+	case key == input.KeyArrowLeft || char == '<' || char == ',':
+		return navigation.DirLeft
+	case key == input.KeyArrowRight || char == '>' || char == '.':
+		return navigation.DirRight
+	case key == input.KeyArrowUp:
+		return navigation.DirUp
+	case key == input.KeyArrowDown:
+		return navigation.DirDown
+	case key == input.KeyHome:
+		return navigation.DirHome
+	case key == input.KeyEnd:
+		return navigation.DirEnd
+	}
+	return navigation.DirNone
+}
+
+// calendarGrid returns the grid for state's year: days not open yet are
+// locked, days unlocked since the caller's last visit are new until they open
+// them, and other open days are open or opened
+func calendarGrid(navigator *navigation.Navigator, state navigation.State, visits *visits) display.Grid {
+	g := display.Grid{
+		Title:    fmt.Sprintf("Advent %d", state.CurrentYear),
+		Selected: state.Cursor,
+	}
+	fresh := make(map[int]bool)
+	for _, day := range visits.unlockedSince(navigator, state.CurrentYear) {
+		fresh[day] = true
+	}
+	for i := range g.Doors {
+		day := i + 1
+		switch {
+		case day > state.MaxDay:
+			g.Doors[i] = display.DoorLocked
+		case visits.opened(state.CurrentYear, day):
+			g.Doors[i] = display.DoorOpened
+		case fresh[day]:
+			g.Doors[i] = display.DoorNew
+		default:
+			g.Doors[i] = display.DoorOpen
+		}
+	}
+	return g
+}
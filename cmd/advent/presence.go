@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/navigation"
+	"github.com/robbiew/advent/internal/store"
+)
+
+// presence lists this caller among those in the door, for the sysop menu.
+// A nil *presence (no store) records nothing.
+type presence struct {
+	store *store.Store
+	entry store.Presence
+}
+
+// newPresence tracks user on their node in st
+func newPresence(st *store.Store, user display.User) *presence {
+	if st == nil {
+		return nil
+	}
+	return &presence{store: st, entry: store.Presence{
+		BBS:   user.BBSName,
+		Node:  user.NodeNum,
+		Alias: user.Alias,
+		Since: time.Now(),
+	}}
+}
+
+// seen records what the caller is looking at
+func (p *presence) seen(state navigation.State) {
+	if p == nil {
+		return
+	}
+	if err := p.store.Enter(p.at(state)); err != nil {
+		logrus.WithError(err).WithField("node", p.entry.Node).Warn("Failed to record presence")
+	}
+}
+
+// at moves the caller's entry to what they're looking at now and returns it
+func (p *presence) at(state navigation.State) store.Presence {
+	p.entry.Where = describeState(state)
+	p.entry.Seen = time.Now()
+	return p.entry
+}
+
+// leave removes the caller from the list
+func (p *presence) leave() {
+	if p == nil {
+		return
+	}
+	if err := p.store.Leave(p.entry.BBS, p.entry.Node); err != nil {
+		logrus.WithError(err).WithField("node", p.entry.Node).Warn("Failed to clear presence")
+	}
+}
+
+// describeState returns where a caller is, such as "2025 day 5"
+func describeState(state navigation.State) string {
+	switch state.Screen {
+	case navigation.ScreenDay:
+		return fmt.Sprintf("%d day %d", state.CurrentYear, state.CurrentDay)
+	case navigation.ScreenCustom:
+		return fmt.Sprintf("%d %s", state.CurrentYear, state.Custom)
+	}
+	return fmt.Sprintf("%d %s", state.CurrentYear, state.Screen)
+}
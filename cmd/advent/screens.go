@@ -0,0 +1,376 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/navigation"
+)
+
+// welcomeScreen shows the year's welcome art, or its teaser while none of
+// its days are open
+type welcomeScreen struct {
+	basicScreen
+}
+
+func (s *welcomeScreen) Render(a *App) {
+	// Returning callers see what's new along the bottom; a teaser lists the
+	// years that can be browsed instead
+	if a.state.MaxDay < 1 {
+		a.displayEngine.SetNotice(teaserNotice(a.navigator, a.state))
+		a.display(a.artManager.GetPath(a.state.CurrentYear, 0, "teaser"))
+		return
+	}
+	a.displayEngine.SetNotice(a.visits.notice(a.navigator, a.state))
+	a.display(a.artManager.GetPath(a.state.CurrentYear, 0, "welcome"))
+}
+
+func (s *welcomeScreen) HandleKey(a *App, char rune, key input.Key) {
+	browseKey(a, char, key, true)
+}
+
+// comebackScreen follows the newest open day until the next one opens
+type comebackScreen struct {
+	basicScreen
+}
+
+func (s *comebackScreen) Render(a *App) {
+	a.displayEngine.SetNotice(a.visits.notice(a.navigator, a.state))
+	a.display(a.artManager.GetPath(a.state.CurrentYear, 0, "comeback"))
+}
+
+func (s *comebackScreen) HandleKey(a *App, char rune, key input.Key) {
+	browseKey(a, char, key, true)
+}
+
+// dayScreen shows a day's door, with the art credit when C toggles it on
+type dayScreen struct {
+	basicScreen
+}
+
+func (s *dayScreen) Render(a *App) {
+	credit := ""
+	if a.showCredits {
+		credit = a.artManager.Credit(a.state.CurrentYear, a.state.CurrentDay)
+	}
+	a.displayEngine.SetCredit(credit)
+	a.displayEngine.SetNotice(a.visits.notice(a.navigator, a.state))
+	a.display(a.artManager.GetPath(a.state.CurrentYear, a.state.CurrentDay, "day"))
+}
+
+func (s *dayScreen) HandleKey(a *App, char rune, key input.Key) {
+	if char == 'c' || char == 'C' {
+		a.showCredits = !a.showCredits
+		logrus.WithField("showCredits", a.showCredits).Debug("Credit line toggled")
+		a.Redraw()
+		return
+	}
+	browseKey(a, char, key, false)
+}
+
+// Leave takes the credit down so other screens don't show it
+func (s *dayScreen) Leave(a *App) {
+	a.displayEngine.SetCredit("")
+}
+
+// browseKey handles the keys the welcome, comeback and day screens share.
+// With years set (welcome and comeback) the number keys choose a year, I
+// and M open the info and members screens, and Enter goes to the day.
+func browseKey(a *App, char rune, key input.Key, years bool) {
+	if a.sysopKey(char) {
+		return
+	}
+
+	switch {
+	case char == 'y' || char == 'Y':
+		a.state = a.navigator.OpenYearSelect(a.state)
+		return
+	case char == 'g' || char == 'G':
+		a.state = a.navigator.OpenCalendar(a.state)
+		return
+	case char == 'q' || char == 'Q' || key == input.KeyEsc:
+		a.state = a.navigator.Back(a.state, a.yearOpen)
+		if a.state.Screen == navigation.ScreenExit {
+			logrus.Info("User requested exit from latest year's welcome screen")
+		} else {
+			logrus.WithField("year", a.state.CurrentYear).Info("User requested return to welcome screen")
+		}
+		return
+	case years && char >= '1' && char <= '9':
+		selectYearByIndex(a, int(char-'0'))
+		return
+	case char == 'n' || char == 'N':
+		openNewestDay(a)
+		return
+	case years && (char == 'i' || char == 'I'):
+		if screenAllowed(a.displayEngine, a.validator, a.user, navigation.ScreenInfo) {
+			a.state = a.navigator.OpenScreen(a.state, navigation.ScreenInfo)
+		}
+		return
+	case years && (char == 'm' || char == 'M'):
+		if screenAllowed(a.displayEngine, a.validator, a.user, navigation.ScreenMembers) {
+			a.state = a.navigator.OpenScreen(a.state, navigation.ScreenMembers)
+		}
+		return
+	}
+
+	// The sysop's own screens
+	if hk, ok := a.hotkeys[unicode.ToUpper(char)]; ok {
+		if a.user.SecurityLevel < hk.level {
+			logrus.WithFields(logrus.Fields{"screen": hk.name, "required": hk.level}).Info("Screen restricted by security level")
+			a.displayEngine.UpdateNotice(fmt.Sprintf("That screen needs security level %d", hk.level))
+			return
+		}
+		a.state = a.navigator.OpenCustom(a.state, hk.name)
+		return
+	}
+
+	var direction navigation.Direction
+
+	// RETURN on the welcome and comeback screens goes to the current day (same as arrow right)
+	if years && key == input.KeyEnter {
+		direction = navigation.DirRight
+	}
+
+	// < and > for terminals without arrow keys (ASCII callers)
+	switch char {
+	case '>', '.':
+		direction = navigation.DirRight
+	case '<', ',':
+		direction = navigation.DirLeft
+	}
+
+	switch key {
+	case input.KeyArrowRight:
+		direction = navigation.DirRight
+	case input.KeyArrowLeft:
+		direction = navigation.DirLeft
+	case input.KeyPageUp:
+		direction = navigation.DirPageUp
+	case input.KeyPageDown:
+		direction = navigation.DirPageDown
+	case input.KeyHome:
+		direction = navigation.DirHome
+	case input.KeyEnd:
+		direction = navigation.DirEnd
+	}
+	if direction == navigation.DirNone {
+		return
+	}
+
+	newState, _, err := a.navigator.Navigate(direction, a.state)
+	if err != nil {
+		logrus.WithError(err).Error("Navigation error")
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"direction": direction,
+		"newDay":    newState.CurrentDay,
+		"newScreen": newState.Screen,
+	}).Debug("Navigation result")
+	a.state = newState
+}
+
+// selectYearByIndex switches to one of the first nine years with its number key
+func selectYearByIndex(a *App, index int) {
+	newState, _, err := a.navigator.SelectYearByIndex(index, a.state)
+	if err != nil {
+		// Invalid selection, just ignore
+		logrus.WithField("index", index).Debug("Invalid year selection")
+		return
+	}
+	if !a.yearAllowed(newState.CurrentYear) {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"index":        index,
+		"selectedYear": newState.CurrentYear,
+	}).Info("Year selected")
+	a.state = newState
+}
+
+// openNewestDay jumps to the newest day of this season the caller hasn't opened
+func openNewestDay(a *App) {
+	day := a.visits.newestUnopened(a.navigator, a.state)
+	if day < 1 {
+		return
+	}
+	logrus.WithField("day", day).Info("Jumping to newest unopened day")
+
+	state := a.state
+	if latest := state.AvailableYears[len(state.AvailableYears)-1]; state.CurrentYear != latest {
+		if !a.yearAllowed(latest) {
+			return
+		}
+		state, _, _ = a.navigator.SelectYear(latest, state)
+	}
+	if state, _, err := a.navigator.OpenDay(state, day); err == nil {
+		a.state = state
+	}
+}
+
+// pageScreen shows art in a scrolling viewer with the menu bar below it: the
+// year's info or members screen, or one of the sysop's own screens
+type pageScreen struct {
+	basicScreen
+	kind string // The year's art of this kind, such as "info"
+	path string // Or this art file, for the sysop's screens
+}
+
+func (s *pageScreen) Render(a *App) {
+	path := s.path
+	if s.kind != "" {
+		path = a.artManager.GetPath(a.state.CurrentYear, 0, s.kind)
+	}
+	a.displayEngine.SetNotice(a.visits.notice(a.navigator, a.state))
+
+	lines, err := a.displayEngine.LoadAnsiLines(path)
+	if err != nil {
+		logrus.WithError(err).WithField("path", path).Warn("Failed to load screen art")
+		lines = []string{fmt.Sprintf("[Unable to load %s]", path)}
+	}
+	a.viewer = a.displayEngine.NewViewer(lines, true)
+	a.viewer.Draw()
+}
+
+func (s *pageScreen) HandleKey(a *App, char rune, key input.Key) {
+	if a.sysopKey(char) {
+		return
+	}
+	if char == 'q' || char == 'Q' || key == input.KeyEsc {
+		a.state = a.navigator.Back(a.state, a.yearOpen)
+	}
+}
+
+// calendarScreen is the generated grid of the year's days
+type calendarScreen struct {
+	basicScreen
+	picker dayPicker
+}
+
+// Enter starts without a day number typed
+func (s *calendarScreen) Enter(a *App) {
+	s.picker.typed = ""
+}
+
+func (s *calendarScreen) Render(a *App) {
+	a.displayEngine.SetNotice(s.picker.notice())
+	a.displayEngine.DisplayGrid(calendarGrid(a.navigator, a.state, a.visits))
+}
+
+func (s *calendarScreen) HandleKey(a *App, char rune, key input.Key) {
+	if a.sysopKey(char) {
+		return
+	}
+	a.state = s.picker.handle(char, key, a.state)
+}
+
+// yearSelectScreen lists the years beside a preview of the one under the cursor
+type yearSelectScreen struct {
+	basicScreen
+}
+
+func (s *yearSelectScreen) Render(a *App) {
+	a.displayEngine.SetNotice(yearSelectHelp)
+	a.displayEngine.DisplayGallery(yearGallery(a.navigator, a.artManager, a.validator, a.state, a.user, a.yearUnlocked))
+}
+
+func (s *yearSelectScreen) HandleKey(a *App, char rune, key input.Key) {
+	if a.sysopKey(char) {
+		return
+	}
+	switch {
+	case key == input.KeyEsc || char == 'q' || char == 'Q':
+		a.state = a.navigator.Back(a.state, a.yearOpen)
+	case key == input.KeyEnter:
+		if !a.yearAllowed(a.state.Cursor) {
+			return
+		}
+		if newState, _, err := a.navigator.SelectYear(a.state.Cursor, a.state); err == nil {
+			logrus.WithField("selectedYear", newState.CurrentYear).Info("Year selected")
+			a.state = newState
+		}
+	default:
+		if direction := cursorDirection(char, key); direction != navigation.DirNone {
+			a.state, _, _ = a.navigator.Navigate(direction, a.state)
+		}
+	}
+}
+
+// notYetTimeout is how long the countdown screen waits for a key
+const notYetTimeout = 10 * time.Second
+
+// notYetScreen shows the coming year's teaser (NOTYET.ANS when it has none)
+// with a countdown to the first day, ticking each second until a key is
+// pressed or notYetTimeout passes
+type notYetScreen struct {
+	basicScreen
+	start    time.Time // When the first day opens
+	deadline time.Time
+}
+
+func (s *notYetScreen) Enter(a *App) {
+	s.start = a.navigator.SeasonStart()
+	s.deadline = time.Now().Add(notYetTimeout)
+}
+
+func (s *notYetScreen) Render(a *App) {
+	a.displayEngine.SetNotice(countdownNotice(a.navigator, s.start))
+	if path := a.artManager.GetPath(s.start.Year(), 0, "teaser"); path != "" {
+		a.displayEngine.Display(path, a.user)
+	}
+}
+
+func (s *notYetScreen) HandleKey(a *App, char rune, key input.Key) {
+	logrus.Info("NOTYET: key pressed, exiting")
+	a.Quit()
+}
+
+func (s *notYetScreen) Tick(a *App) {
+	if time.Now().After(s.deadline) {
+		logrus.Info("NOTYET: timeout reached, exiting")
+		a.Quit()
+		return
+	}
+	a.displayEngine.UpdateNotice(countdownNotice(a.navigator, s.start))
+}
+
+// goodbyeTimeout is how long the goodbye screen waits for a key
+const goodbyeTimeout = 10 * time.Second
+
+// goodbyeScreen shows the year's goodbye art on the way out until a key is
+// pressed or goodbyeTimeout passes (no visible prompt)
+type goodbyeScreen struct {
+	basicScreen
+	deadline time.Time
+}
+
+func (s *goodbyeScreen) Enter(a *App) {
+	s.deadline = time.Now().Add(goodbyeTimeout)
+}
+
+func (s *goodbyeScreen) Render(a *App) {
+	path := a.artManager.GetPath(a.state.CurrentYear, 0, "goodbye")
+	if path == "" {
+		a.Quit()
+		return
+	}
+	a.displayEngine.SetNotice("")
+	a.displayEngine.Display(path, a.user)
+}
+
+func (s *goodbyeScreen) HandleKey(a *App, char rune, key input.Key) {
+	logrus.Info("GOODBYE: key pressed, exiting")
+	a.Quit()
+}
+
+func (s *goodbyeScreen) Tick(a *App) {
+	if time.Now().After(s.deadline) {
+		logrus.Info("GOODBYE: timeout reached, exiting")
+		a.Quit()
+	}
+}
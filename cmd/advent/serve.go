@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/robbiew/advent/internal/bbs"
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/clock"
+	"github.com/robbiew/advent/internal/config"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/server"
+	"github.com/robbiew/advent/internal/session"
+	"github.com/robbiew/advent/internal/store"
+)
+
+// Listen addresses used when -serve is given without one
+const (
+	defaultTelnetAddr = ":2323"
+	defaultSSHAddr    = ":2222"
+	defaultWebAddr    = ":8080"
+)
+
+// runServer accepts callers directly over the network until the listener fails
+func runServer(cfg *config.Config, artFS fs.FS, resolver *calendar.Resolver, clk clock.Clock, protocol, addr string) error {
+	st := openStore(cfg)
+	handler := func(sess *server.Session) {
+		serveCaller(cfg, artFS, resolver, clk, st, sess)
+	}
+	nodes := server.NewNodes(cfg.Server.MaxNodes)
+
+	switch protocol {
+	case "telnet":
+		if addr == "" {
+			addr = defaultTelnetAddr
+		}
+		srv := &server.TelnetServer{
+			Addr:        addr,
+			Nodes:       nodes,
+			IdleTimeout: cfg.Server.IdleTimeout.Std(),
+			Handler:     handler,
+		}
+		fmt.Fprintf(os.Stderr, "advent: telnet/rlogin server listening on %s (%d nodes)\n", addr, cfg.Server.MaxNodes)
+		return srv.ListenAndServe()
+	case "ssh":
+		if addr == "" {
+			addr = defaultSSHAddr
+		}
+		hostKey, err := server.LoadOrCreateHostKey(cfg.SSHHostKeyPath())
+		if err != nil {
+			return err
+		}
+		var authorized []server.AuthorizedKey
+		if cfg.Server.SSHAuthorizedKeys != "" {
+			if authorized, err = server.LoadAuthorizedKeys(cfg.Server.SSHAuthorizedKeys); err != nil {
+				return err
+			}
+		}
+		srv := &server.SSHServer{
+			Addr:           addr,
+			Nodes:          nodes,
+			IdleTimeout:    cfg.Server.IdleTimeout.Std(),
+			HostKey:        hostKey,
+			AuthorizedKeys: authorized,
+			Handler:        handler,
+		}
+		login := "guest logins"
+		if len(authorized) > 0 {
+			login = fmt.Sprintf("%d authorized keys", len(authorized))
+		}
+		fmt.Fprintf(os.Stderr, "advent: ssh server listening on %s (%d nodes, %s, host key %s)\n",
+			addr, cfg.Server.MaxNodes, login, ssh.FingerprintSHA256(hostKey.PublicKey()))
+		return srv.ListenAndServe()
+	case "web":
+		if addr == "" {
+			addr = defaultWebAddr
+		}
+		srv := &server.WebServer{
+			Addr:        addr,
+			Nodes:       nodes,
+			IdleTimeout: cfg.Server.IdleTimeout.Std(),
+			Handler:     handler,
+		}
+		fmt.Fprintf(os.Stderr, "advent: web terminal listening on %s (%d nodes)\n", addr, cfg.Server.MaxNodes)
+		return srv.ListenAndServe()
+	default:
+		return fmt.Errorf("unknown server protocol %q (supported: telnet, ssh, web)", protocol)
+	}
+}
+
+// serveCaller runs a full door session for one network caller
+func serveCaller(cfg *config.Config, artFS fs.FS, resolver *calendar.Resolver, clk clock.Clock, st *store.Store, sess *server.Session) {
+	artManager, navigator, validator := newCalendarComponents(artFS, resolver, clk)
+	validator.SetAccess(cfg.AccessLevels())
+
+	// Buffer output so each screen goes out in a few packets; the display
+	// engine flushes after every screen
+	out := bufio.NewWriter(sess)
+
+	width, height := sess.Size()
+	if width <= 0 || height <= 0 {
+		width, height = detectCallerSize(out, sess, cfg.Display.NoDetect)
+	}
+
+	displayEngine := display.NewDisplayEngine(cfg.DisplayConfig(display.ModeCP437Raw, width, height), artFS)
+	displayEngine.SetBBSConnection(out)
+
+	inputHandler := input.NewInputHandler()
+	inputHandler.SetReader(sess)
+
+	alias := sess.Username
+	if alias == "" {
+		alias = "Guest"
+	}
+	user := display.User{
+		Alias:     alias,
+		TimeLeft:  cfg.Session.MaxTimeout.Std(),
+		Emulation: 1,
+		NodeNum:   sess.Node,
+		H:         height,
+		W:         width,
+		ModalH:    height,
+		ModalW:    width,
+	}
+
+	// Timeouts hang up on this caller only - the server keeps running
+	var sessionManager *session.Manager
+	hangup := func() {
+		cleanup(displayEngine, inputHandler, sessionManager)
+		sess.Close()
+	}
+	sessionManager = session.NewManager(cfg.Session.IdleTimeout.Std(), cfg.Session.MaxTimeout.Std(), hangup, hangup)
+
+	d := &door{
+		artManager:     artManager,
+		navigator:      navigator,
+		validator:      validator,
+		displayEngine:  displayEngine,
+		inputHandler:   inputHandler,
+		sessionManager: sessionManager,
+		user:           user,
+		visits:         newVisits(st, user, sess.Authenticated, clk), // Only verified logins are remembered
+		presence:       newPresence(st, user),
+		archive:        cfg.ArchiveOffSeason(),
+		screens:        cfg.Screens,
+		started:        time.Now(),
+		terminalSize:   sess.Size,
+	}
+	sess.OnResize(func(width, height int) { inputHandler.Wake() })
+	d.sysop = newSysopMenu(d, cfg, st, clk)
+	d.run()
+}
+
+// detectCallerSize asks the caller's terminal for its size when the client
+// did not report one during negotiation
+func detectCallerSize(out *bufio.Writer, sess *server.Session, noDetect bool) (int, int) {
+	if noDetect {
+		return 80, 25
+	}
+	width, height, err := bbs.DetectTerminalSize(out, sess)
+	if err != nil || width <= 0 || height <= 0 {
+		logrus.WithError(err).WithField("node", sess.Node).Info("Could not detect caller's terminal size, using 80x25")
+		return 80, 25
+	}
+	return width, height
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/clock"
+	"github.com/robbiew/advent/internal/config"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/navigation"
+	"github.com/robbiew/advent/internal/session"
+	"github.com/robbiew/advent/internal/store"
+	"github.com/robbiew/advent/internal/validation"
+)
+
+// sysopMenuKey opens the sysop menu. It isn't shown anywhere.
+const sysopMenuKey = '!'
+
+// sysopMenu is the hidden menu for callers at the configured sysop level:
+// simulate a date, look past locks, reload art and see who's calling
+type sysopMenu struct {
+	displayEngine  *display.DisplayEngine
+	navigator      *navigation.Navigator
+	validator      *validation.Validator
+	inputHandler   *input.InputHandler
+	sessionManager *session.Manager
+	visits         *visits        // Nil when visits aren't remembered
+	store          *store.Store   // Nil when user data is unavailable
+	clock          clock.Clock    // The door's own clock, restored by a blank date
+	zone           *time.Location // Simulated dates without a zone are in the unlock zone
+	staleAfter     time.Duration  // Callers not seen for this long have left
+	simulated      bool           // Whether the date was set from the menu
+	dateCheckOff   bool           // Whether the date check was off before locks were ignored
+}
+
+// newSysopMenu returns the menu for d's caller, or nil when their security
+// level doesn't open it
+func newSysopMenu(d *door, cfg *config.Config, st *store.Store, clk clock.Clock) *sysopMenu {
+	if !d.validator.IsSysop(d.user.SecurityLevel) {
+		return nil
+	}
+	return &sysopMenu{
+		displayEngine:  d.displayEngine,
+		navigator:      d.navigator,
+		validator:      d.validator,
+		inputHandler:   d.inputHandler,
+		sessionManager: d.sessionManager,
+		visits:         d.visits,
+		store:          st,
+		clock:          clk,
+		zone:           cfg.UnlockRule().Zone(),
+		staleAfter:     cfg.Session.IdleTimeout.Std() + time.Minute,
+	}
+}
+
+// open runs the menu until the sysop leaves it, applying changes to the
+// date, locks or art to state
+func (m *sysopMenu) open(state *navigation.State) {
+	logrus.Info("Sysop menu opened")
+	for {
+		m.displayEngine.SetNotice("")
+		m.displayEngine.DisplayText(m.menuLines(*state))
+
+		char, key, err := m.inputHandler.ReadKey()
+		if err != nil {
+			return
+		}
+		m.sessionManager.ResetIdleTimer()
+
+		switch {
+		case key == input.KeyEsc || char == 'q' || char == 'Q':
+			return
+		case char == 'd' || char == 'D':
+			m.simulateDate(state)
+		case char == 'l' || char == 'L':
+			m.toggleLocks(state)
+		case char == 'r' || char == 'R':
+			m.reload(state)
+		case char == 'v' || char == 'V':
+			m.show(m.viewCountLines(state.CurrentYear))
+		case char == 'w' || char == 'W':
+			m.show(m.presentLines())
+		}
+	}
+}
+
+// menuLines returns the menu with the current settings
+func (m *sysopMenu) menuLines(state navigation.State) []string {
+	now := m.navigator.Now().In(m.zone).Format("2006-01-02 15:04 MST")
+	if m.simulated {
+		now += " (simulated)"
+	}
+	locks := "off"
+	if m.validator.IgnoringLocks() {
+		locks = "on"
+	}
+	return []string{
+		"",
+		"  SYSOP MENU",
+		"",
+		"  [D] Simulate a date          " + now,
+		"  [L] Ignore locks             " + locks,
+		"  [R] Reload art from disk",
+		fmt.Sprintf("  [V] View counts for %d", state.CurrentYear),
+		"  [W] Who's in the door",
+		"  [Q] Back",
+	}
+}
+
+// show displays lines until a key is pressed
+func (m *sysopMenu) show(lines []string) {
+	m.displayEngine.DisplayText(append(lines, "", "  Press any key"))
+	if _, _, err := m.inputHandler.ReadKey(); err == nil {
+		m.sessionManager.ResetIdleTimer()
+	}
+}
+
+// simulateDate runs the calendar for this session at a date the sysop
+// types, or back on the door's own clock when left blank
+func (m *sysopMenu) simulateDate(state *navigation.State) {
+	value, ok := readLine(m.displayEngine, m.inputHandler, m.sessionManager,
+		"Date (YYYY-MM-DD or YYYY-MM-DDTHH:MM, blank to reset): ")
+	if !ok {
+		return
+	}
+	clk := m.clock
+	m.simulated = strings.TrimSpace(value) != ""
+	if m.simulated {
+		start, err := parseDebugDate(strings.TrimSpace(value), m.zone)
+		if err != nil {
+			m.displayEngine.UpdateNotice(err.Error())
+			m.simulated = false
+			time.Sleep(2 * time.Second)
+			return
+		}
+		clk = clock.Simulated(start)
+	}
+	m.navigator.SetClock(clk)
+	m.validator.SetClock(clk)
+	m.visits.simulate(clk != clock.System)
+	logrus.WithField("time", m.navigator.Now()).Info("Sysop changed the session's date")
+	m.refresh(state)
+}
+
+// toggleLocks turns ignoring keys, security levels and unlock dates on or off
+func (m *sysopMenu) toggleLocks(state *navigation.State) {
+	ignore := !m.validator.IgnoringLocks()
+	if ignore {
+		m.dateCheckOff = m.navigator.DateCheckDisabled()
+		m.navigator.SetDisableDateCheck(true)
+	} else {
+		m.navigator.SetDisableDateCheck(m.dateCheckOff)
+	}
+	m.validator.SetIgnoreLocks(ignore)
+	logrus.WithField("ignoreLocks", ignore).Info("Sysop toggled locks")
+	m.refresh(state)
+}
+
+// reload forgets cached art and manifests so changes on disk show up
+func (m *sysopMenu) reload(state *navigation.State) {
+	m.navigator.Reload()
+	m.displayEngine.ClearCache()
+	if years, err := m.navigator.GetAvailableYears(); err == nil && len(years) > 0 {
+		state.AvailableYears = years
+	}
+	logrus.WithField("years", state.AvailableYears).Info("Sysop reloaded art")
+	m.refresh(state)
+}
+
+// refresh brings state in line with the date and locks now in effect
+func (m *sysopMenu) refresh(state *navigation.State) {
+	state.MaxDay = m.navigator.MaxDay(state.CurrentYear)
+	if state.CurrentDay > state.MaxDay {
+		state.CurrentDay = state.MaxDay
+	}
+	if state.CurrentDay < 1 {
+		state.CurrentDay = 1
+	}
+	if state.MaxDay < 1 && (state.Screen == navigation.ScreenDay || state.Screen == navigation.ScreenComeback) {
+		state.Screen = navigation.ScreenWelcome
+	}
+}
+
+// viewCountLines charts how many callers have opened each day of a year
+func (m *sysopMenu) viewCountLines(year int) []string {
+	lines := []string{"", fmt.Sprintf("  VIEW COUNTS FOR %d", year), ""}
+	if m.store == nil {
+		return append(lines, "  User data is unavailable (see data.dir)")
+	}
+	counts, err := m.store.ViewCounts(year)
+	if err != nil {
+		return append(lines, "  "+err.Error())
+	}
+
+	most := 1
+	for _, n := range counts {
+		if n > most {
+			most = n
+		}
+	}
+
+	// Two columns (days 1-13 and 14-25) so the chart fits on one screen
+	const barWidth = 20
+	column := func(day int) string {
+		bar := strings.Repeat("#", counts[day]*barWidth/most)
+		return fmt.Sprintf("Day %2d %5d %-*s", day, counts[day], barWidth, bar)
+	}
+	for day := 1; day <= 13; day++ {
+		line := "  " + column(day)
+		if day+13 < len(counts) {
+			line += "   " + column(day+13)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// presentLines lists the callers in the door on every node
+func (m *sysopMenu) presentLines() []string {
+	lines := []string{"", "  WHO'S IN THE DOOR", ""}
+	if m.store == nil {
+		return append(lines, "  User data is unavailable (see data.dir)")
+	}
+	present, err := m.store.Present(time.Now(), m.staleAfter)
+	if err != nil {
+		return append(lines, "  "+err.Error())
+	}
+	if len(present) == 0 {
+		return append(lines, "  Nobody")
+	}
+	for _, p := range present {
+		node := fmt.Sprintf("%d", p.Node)
+		if p.BBS != "" {
+			node = p.BBS + "/" + node
+		}
+		lines = append(lines, fmt.Sprintf("  %-16s %-20s %-16s since %s",
+			node, p.Alias, p.Where, p.Since.Local().Format("15:04")))
+	}
+	return lines
+}
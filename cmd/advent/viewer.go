@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+	"github.com/robbiew/advent/internal/session"
+)
+
+// viewKey applies a scrolling or search key to the screen's viewer and
+// reports whether it was one. n and N only repeat a search that found
+// something, so N still jumps to the newest day otherwise.
+func viewKey(v *display.Viewer, displayEngine *display.DisplayEngine, inputHandler *input.InputHandler,
+	sessionManager *session.Manager, char rune, key input.Key) bool {
+	switch {
+	case key == input.KeyArrowUp:
+		v.Scroll(-1)
+	case key == input.KeyArrowDown:
+		v.Scroll(1)
+	case key == input.KeyPageUp:
+		v.Page(-1)
+	case key == input.KeyPageDown:
+		v.Page(1)
+	case key == input.KeyHome:
+		v.Home()
+	case key == input.KeyEnd:
+		v.End()
+	case char == '/':
+		notice := displayEngine.Notice()
+		query, ok := readLine(displayEngine, inputHandler, sessionManager, "Search: ")
+		displayEngine.SetNotice(notice)
+		if ok {
+			v.Search(query)
+		} else {
+			v.Draw()
+		}
+	case char == 'n' && v.Searching():
+		v.Next()
+	case char == 'N' && v.Searching():
+		v.Prev()
+	default:
+		return false
+	}
+	return true
+}
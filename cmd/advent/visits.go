@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/clock"
+	"github.com/robbiew/advent/internal/config"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/navigation"
+	"github.com/robbiew/advent/internal/store"
+)
+
+// visits records one caller's session in the user store. A nil *visits
+// (no store, or a caller we can't tell apart from others) records nothing.
+type visits struct {
+	store   *store.Store
+	key     store.Key
+	last    store.Position
+	visitor store.Visitor // Latest copy of the caller's record
+	since   time.Time     // Start of the previous session, zero on a first visit
+	clock   clock.Clock
+	// simulated is set while the session runs on a simulated date, when
+	// opening days isn't recorded: they may not really be open yet
+	simulated bool
+}
+
+// openStore opens the configured data directory, or returns nil (and the
+// door runs without remembering anything) when it can't be used
+func openStore(cfg *config.Config) *store.Store {
+	st, err := store.Open(cfg.DataDir())
+	if err != nil {
+		logrus.WithError(err).Warn("User data unavailable - visits will not be remembered")
+		return nil
+	}
+	return st
+}
+
+// newVisits tracks user in st; known is false for fallback or guest
+// identities shared by many callers
+func newVisits(st *store.Store, user display.User, known bool, clk clock.Clock) *visits {
+	key := store.Key{BBS: user.BBSName, Alias: user.Alias, Record: user.UserRecord}
+	if st == nil || !known || !key.Valid() {
+		return nil
+	}
+	return &visits{store: st, key: key, clock: clk, simulated: clk != clock.System}
+}
+
+// simulate sets whether the session runs on a simulated date
+func (v *visits) simulate(simulated bool) {
+	if v != nil {
+		v.simulated = simulated
+	}
+}
+
+// start records a new session
+func (v *visits) start() {
+	if v == nil {
+		return
+	}
+	visitor, err := v.store.Update(v.key, func(visitor *store.Visitor) {
+		v.since = visitor.LastVisit
+		// Always the real time, so a preview of a later date can't hide
+		// what's new from the caller's next real visit
+		visitor.Visit(clock.System.Now())
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("user", v.key.String()).Warn("Failed to record visit")
+		return
+	}
+	v.visitor = visitor
+	logrus.WithFields(logrus.Fields{
+		"user":      v.key.String(),
+		"visits":    visitor.Visits,
+		"lastVisit": v.since,
+	}).Info("Recorded visit")
+}
+
+// viewed records the screen the caller is looking at, refreshing p's place
+// in the presence list in the same write to the data file
+func (v *visits) viewed(state navigation.State, p *presence) {
+	change := v.change(state)
+	if change == nil {
+		p.seen(state)
+		return
+	}
+
+	var visitor store.Visitor
+	var err error
+	if p == nil {
+		visitor, err = v.store.Update(v.key, change)
+	} else {
+		visitor, err = v.store.UpdateAndEnter(v.key, change, p.at(state))
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("user", v.key.String()).Warn("Failed to record viewed screen")
+		return
+	}
+	v.visitor = visitor
+}
+
+// change returns the update recording a screen the caller has moved to, or
+// nil when there's nothing to record
+func (v *visits) change(state navigation.State) func(*store.Visitor) {
+	if v == nil || v.simulated {
+		return nil
+	}
+	pos := store.Position{Year: state.CurrentYear, Screen: state.Screen.String()}
+	if state.Screen == navigation.ScreenDay {
+		pos.Day = state.CurrentDay
+	}
+	if pos == v.last {
+		return nil
+	}
+	v.last = pos
+
+	return func(visitor *store.Visitor) {
+		if pos.Day > 0 {
+			visitor.ViewDay(pos.Year, pos.Day)
+			visitor.LastDay = pos
+		} else {
+			visitor.ViewYear(pos.Year)
+		}
+		visitor.LastScreen = pos
+	}
+}
+
+// opened reports whether the caller has opened a day before. Callers whose
+// visits aren't remembered haven't opened anything.
+func (v *visits) opened(year, day int) bool {
+	return v != nil && v.visitor.Viewed(year, day)
+}
+
+// unlocked reports whether the caller has entered a year's key before
+func (v *visits) unlocked(year int) bool {
+	return v != nil && v.visitor.HasUnlocked(year)
+}
+
+// unlock remembers that the caller entered a year's key
+func (v *visits) unlock(year int) {
+	if v == nil {
+		return
+	}
+	visitor, err := v.store.Update(v.key, func(visitor *store.Visitor) {
+		visitor.UnlockYear(year)
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("user", v.key.String()).Warn("Failed to record unlocked year")
+		return
+	}
+	v.visitor = visitor
+}
+
+// resume points state at the day the caller last opened, so Enter on the
+// welcome screen carries on from there
+func (v *visits) resume(navigator *navigation.Navigator, state *navigation.State) {
+	if v == nil || v.visitor.LastDay.Day == 0 {
+		return
+	}
+	last := v.visitor.LastDay
+	for _, year := range state.AvailableYears {
+		if year != last.Year {
+			continue
+		}
+		maxDay := navigator.MaxDay(year)
+		if maxDay < 1 {
+			return
+		}
+		state.CurrentYear = year
+		state.MaxDay = maxDay
+		state.CurrentDay = last.Day
+		if state.CurrentDay > state.MaxDay {
+			state.CurrentDay = state.MaxDay
+		}
+		logrus.WithFields(logrus.Fields{
+			"year": state.CurrentYear,
+			"day":  state.CurrentDay,
+		}).Info("Resuming from last visit")
+		return
+	}
+}
+
+// newestUnopened returns the latest open day of the newest year the caller
+// hasn't looked at yet, or 0 when they've seen them all
+func (v *visits) newestUnopened(navigator *navigation.Navigator, state navigation.State) int {
+	year := state.AvailableYears[len(state.AvailableYears)-1]
+	if v == nil {
+		return navigator.MaxDay(year)
+	}
+	return v.visitor.NewestUnopened(year, navigator.MaxDay(year))
+}
+
+// unlockedSince returns the days of a year unlocked since the caller's last
+// visit that they haven't opened yet; nothing is new on a first visit or to
+// callers whose visits aren't remembered
+func (v *visits) unlockedSince(navigator *navigation.Navigator, year int) []int {
+	if v == nil || v.since.IsZero() {
+		return nil
+	}
+	var days []int
+	for _, day := range navigator.UnlockedBetween(year, v.since, v.clock.Now()) {
+		if !v.visitor.Viewed(year, day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// notice returns the welcome screen footer for a returning caller: the days
+// unlocked since their last visit that they haven't opened yet, or where
+// Enter will resume
+func (v *visits) notice(navigator *navigation.Navigator, state navigation.State) string {
+	if v == nil || v.since.IsZero() || state.Screen != navigation.ScreenWelcome {
+		return ""
+	}
+	year := state.AvailableYears[len(state.AvailableYears)-1]
+	var fresh []string
+	for _, day := range v.unlockedSince(navigator, year) {
+		fresh = append(fresh, strconv.Itoa(day))
+	}
+	if len(fresh) > 0 {
+		return fmt.Sprintf("New since your last visit: day %s - press N to open day %d",
+			strings.Join(fresh, ", "), v.newestUnopened(navigator, state))
+	}
+	if state.CurrentDay > 1 {
+		return fmt.Sprintf("Welcome back! Press Enter to continue from day %d", state.CurrentDay)
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/robbiew/advent/internal/art"
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/navigation"
+	"github.com/robbiew/advent/internal/validation"
+)
+
+// yearSelectHelp is the year select screen's notice
+const yearSelectHelp = "Arrows choose a year - Enter opens it - Q back"
+
+// yearGallery lists every available year with how many of its days are
+// open and whether the caller can get in, previewing the year under the
+// cursor. unlocked reports the years the caller has entered keys for.
+func yearGallery(navigator *navigation.Navigator, artManager *art.Manager, validator *validation.Validator,
+	state navigation.State, user display.User, unlocked func(year int) bool) display.Gallery {
+	g := display.Gallery{Title: "Choose a year"}
+	for i, year := range state.AvailableYears {
+		maxDay := navigator.MaxDay(year)
+		detail := openDays(maxDay)
+		if level := validator.YearLevel(year); user.SecurityLevel < level {
+			detail += fmt.Sprintf(" - level %d", level)
+		} else if validator.RequireKey(year) && !unlocked(year) {
+			detail += " - locked"
+		}
+		g.Items = append(g.Items, display.GalleryItem{Label: fmt.Sprint(year), Detail: detail})
+
+		if year == state.Cursor {
+			g.Selected = i
+			g.Preview = artManager.GetPath(year, 0, "welcome")
+			if maxDay < 1 {
+				g.Preview = artManager.GetPath(year, 0, "teaser")
+			}
+		}
+	}
+	return g
+}
+
+// openDays describes how many of a year's days are open, such as "12 of 25 days"
+func openDays(maxDay int) string {
+	switch {
+	case maxDay >= calendar.LastDay:
+		return fmt.Sprintf("all %d days", calendar.LastDay)
+	case maxDay > 0:
+		return fmt.Sprintf("%d of %d days", maxDay, calendar.LastDay)
+	default:
+		return "coming soon"
+	}
+}
@@ -4,11 +4,11 @@ go 1.20
 
 require (
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/crypto v0.17.0
+	golang.org/x/net v0.19.0
+	golang.org/x/sys v0.15.0
 	golang.org/x/term v0.15.0
 	golang.org/x/text v0.14.0
 )
 
-require (
-	github.com/stretchr/testify v1.8.4 // indirect
-	golang.org/x/sys v0.15.0 // indirect
-)
+require github.com/stretchr/testify v1.8.4 // indirect
@@ -8,24 +8,34 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/sauce"
 )
 
 // Manager handles art file management and caching
 type Manager struct {
-	baseDir string
-	cache   map[string][]string
-	fs      fs.FS // Embedded filesystem
+	baseDir  string
+	cache    map[string][]string
+	fs       fs.FS // Embedded filesystem
+	resolver *calendar.Resolver
 }
 
 // NewManager creates a new art manager using embedded filesystem
 func NewManager(embeddedFS fs.FS, baseDir string) *Manager {
 	return &Manager{
-		baseDir: baseDir,
-		cache:   make(map[string][]string),
-		fs:      embeddedFS,
+		baseDir:  baseDir,
+		cache:    make(map[string][]string),
+		fs:       embeddedFS,
+		resolver: calendar.NewResolver(embeddedFS, baseDir),
 	}
 }
 
+// SetResolver shares a calendar resolver with other components
+func (m *Manager) SetResolver(resolver *calendar.Resolver) {
+	m.resolver = resolver
+}
+
 // Validate checks if art files exist for the given year
 func (m *Manager) Validate(year int) error {
 	yearDir := path.Join(m.baseDir, strconv.Itoa(year))
@@ -56,10 +66,8 @@ func (m *Manager) Validate(year int) error {
 
 	// Check daily art files (1-25)
 	for day := 1; day <= 25; day++ {
-		fileName := fmt.Sprintf("%d_DEC%s.ANS", day, strconv.Itoa(year)[2:])
-		filePath := path.Join(yearDir, fileName)
-		if _, err := fs.Stat(m.fs, filePath); err != nil {
-			logrus.WithField("file", fileName).Warn("Daily art file missing")
+		if !m.resolver.DayExists(year, day) {
+			logrus.WithField("file", path.Base(m.resolver.DayPath(year, day))).Warn("Daily art file missing")
 			// Don't fail validation for missing daily files, just warn
 		}
 	}
@@ -69,72 +77,29 @@ func (m *Manager) Validate(year int) error {
 
 // GetPath returns the path to an art file
 func (m *Manager) GetPath(year int, day int, screenType string) string {
-	yearDir := path.Join(m.baseDir, strconv.Itoa(year))
 	commonDir := path.Join(m.baseDir, "common")
 
 	switch screenType {
 	case "welcome":
-		// Always use year-specific WELCOME.ANS
-		return path.Join(yearDir, "WELCOME.ANS")
+		return m.resolver.ScreenPath(year, calendar.ScreenWelcome)
 	case "info":
-		// First check if year-specific INFOFILE.ANS exists
-		yearSpecificInfo := path.Join(yearDir, "INFOFILE.ANS")
-		if _, err := fs.Stat(m.fs, yearSpecificInfo); err == nil {
-			return yearSpecificInfo
-		}
-		// Fall back to root INFOFILE.ANS
-		return path.Join(m.baseDir, "INFOFILE.ANS")
+		// Year-specific INFOFILE.ANS, falling back to the root copy
+		return m.resolver.ScreenPath(year, calendar.ScreenInfo)
 	case "members":
-		// First check if year-specific MEMBERS.ANS exists
-		yearSpecificMembers := path.Join(yearDir, "MEMBERS.ANS")
-		if _, err := fs.Stat(m.fs, yearSpecificMembers); err == nil {
-			return yearSpecificMembers
-		}
-		// Fall back to root MEMBERS.ANS
-		return path.Join(m.baseDir, "MEMBERS.ANS")
+		// Year-specific MEMBERS.ANS, falling back to the root copy
+		return m.resolver.ScreenPath(year, calendar.ScreenMembers)
 	case "goodbye", "exit":
-		// Always use year-specific GOODBYE.ANS (like WELCOME.ANS)
-		return path.Join(yearDir, "GOODBYE.ANS")
+		return m.resolver.ScreenPath(year, calendar.ScreenGoodbye)
 	case "comeback":
-		// Always use year-specific COMEBACK.ANS (like WELCOME.ANS and GOODBYE.ANS)
-		return path.Join(yearDir, "COMEBACK.ANS")
+		return m.resolver.ScreenPath(year, calendar.ScreenComeback)
 	case "day":
-		// Try both zero-padded (01_DEC25.ANS) and single-digit (1_DEC25.ANS) formats
-		yearSuffix := strconv.Itoa(year)[2:]
-
-		// First try the format that matches the year's convention
-		var primaryFileName, fallbackFileName string
-
-		// For 2025 and later, try zero-padded format first
-		if year >= 2025 {
-			// Zero-padded format (e.g., 01_DEC25.ANS)
-			primaryFileName = fmt.Sprintf("%02d_DEC%s.ANS", day, yearSuffix)
-			// Single-digit format as fallback (e.g., 1_DEC25.ANS)
-			fallbackFileName = fmt.Sprintf("%d_DEC%s.ANS", day, yearSuffix)
-		} else {
-			// For 2024 and earlier, try single-digit format first
-			primaryFileName = fmt.Sprintf("%d_DEC%s.ANS", day, yearSuffix)
-			// Zero-padded format as fallback
-			fallbackFileName = fmt.Sprintf("%02d_DEC%s.ANS", day, yearSuffix)
-		}
-
-		// Try primary format first
-		primaryPath := path.Join(yearDir, primaryFileName)
-		if _, err := fs.Stat(m.fs, primaryPath); err == nil {
-			return primaryPath
-		}
-
-		// Try fallback format if primary format doesn't exist
-		fallbackPath := path.Join(yearDir, fallbackFileName)
-		if _, err := fs.Stat(m.fs, fallbackPath); err == nil {
-			return fallbackPath
-		}
-
-		// If neither exists, return the primary format path
-		// This will eventually fall back to MISSING.ANS in the display engine
-		return primaryPath
+		// Manifest entry, or the year's filename convention
+		return m.resolver.DayPath(year, day)
 	case "missing":
 		return path.Join(commonDir, "MISSING.ANS")
+	case "teaser":
+		// Year-specific TEASER.ANS, falling back to the common NOTYET.ANS
+		return m.resolver.ScreenPath(year, calendar.ScreenTeaser)
 	case "notyet":
 		return path.Join(commonDir, "NOTYET.ANS")
 	default:
@@ -155,7 +120,7 @@ func (m *Manager) ListYears() ([]int, error) {
 		if entry.IsDir() {
 			if year, err := strconv.Atoi(entry.Name()); err == nil {
 				// Validate it's a reasonable year
-				if year >= 2020 && year <= 2030 {
+				if calendar.ValidYear(year) {
 					years = append(years, year)
 				}
 			}
@@ -190,8 +155,7 @@ func (m *Manager) LoadArt(filePath string) ([]string, error) {
 // processContent processes raw file content into displayable lines
 func (m *Manager) processContent(content []byte) []string {
 	// Convert to string and trim SAUCE metadata
-	contentStr := string(content)
-	contentStr = m.trimSAUCE(contentStr)
+	contentStr := string(sauce.Strip(content))
 
 	// Split into lines
 	lines := strings.Split(contentStr, "\r\n")
@@ -204,22 +168,42 @@ func (m *Manager) processContent(content []byte) []string {
 	return lines
 }
 
-// trimSAUCE removes SAUCE metadata from content
-func (m *Manager) trimSAUCE(content string) string {
-	// Look for SAUCE marker
-	sauceIndex := strings.Index(content, "SAUCE00")
-	if sauceIndex == -1 {
-		return content
+// SAUCE returns the SAUCE record of an art file, or nil when it has none
+func (m *Manager) SAUCE(filePath string) (*sauce.Record, error) {
+	content, err := fs.ReadFile(m.fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read art file: %w", err)
 	}
+	record, _ := sauce.Parse(content)
+	return record, nil
+}
 
-	// Find the start of SAUCE metadata
-	comntIndex := strings.LastIndex(content[:sauceIndex], "COMNT")
-	if comntIndex == -1 {
-		comntIndex = sauceIndex
+// Credit returns "Day N: title by artist/group" for a day's art. Manifest
+// fields win; anything the manifest leaves out comes from the art's SAUCE
+// record. Returns "" when neither has anything to say.
+func (m *Manager) Credit(year, day int) string {
+	info, _ := m.resolver.DayInfo(year, day)
+	credit := &sauce.Record{Title: info.Title, Author: info.Artist, Group: info.Group}
+
+	if credit.Title == "" || credit.Author == "" || credit.Group == "" {
+		if record, err := m.SAUCE(m.resolver.DayPath(year, day)); err == nil && record != nil {
+			if credit.Title == "" {
+				credit.Title = record.Title
+			}
+			if credit.Author == "" {
+				credit.Author = record.Author
+			}
+			if credit.Group == "" {
+				credit.Group = record.Group
+			}
+		}
 	}
 
-	// Return content before SAUCE metadata
-	return content[:comntIndex]
+	text := credit.Credit()
+	if text == "" {
+		return ""
+	}
+	return fmt.Sprintf("Day %d: %s", day, text)
 }
 
 // IsCached checks if a file is in cache
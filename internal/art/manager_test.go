@@ -0,0 +1,48 @@
+package art
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// withSAUCE appends a minimal SAUCE record to art
+func withSAUCE(art, title, author, group string) []byte {
+	pad := func(s string, n int) string { return s + strings.Repeat(" ", n-len(s)) }
+	record := []byte("SAUCE00" + pad(title, 35) + pad(author, 20) + pad(group, 20) + "20251201")
+	record = binary.LittleEndian.AppendUint32(record, uint32(len(art)))
+	record = append(record, 1, 1) // Character, ANSi
+	record = append(record, make([]byte, 128-len(record))...)
+	return append(append([]byte(art), 0x1A), record...)
+}
+
+func TestCredit(t *testing.T) {
+	manifest := `{"days": [
+		{"day": 1, "file": "01_DEC25.ANS", "title": "Snow", "artist": "j0hnny"},
+		{"day": 3, "file": "03_DEC25.ANS", "title": "Sleigh", "artist": "LDA", "group": "MiSTiGRiS"}
+	]}`
+	mockFS := fstest.MapFS{
+		"art/2025/calendar.json": {Data: []byte(manifest)},
+		"art/2025/01_DEC25.ANS":  {Data: withSAUCE("art", "Ignored", "Ignored", "Mistigris")},
+		"art/2025/02_DEC25.ANS":  {Data: withSAUCE("art", "Humbug!", "Nitron", "n/a")},
+		"art/2025/03_DEC25.ANS":  {Data: []byte("no sauce")},
+		"art/2025/04_DEC25.ANS":  {Data: []byte("no sauce")},
+	}
+	m := NewManager(mockFS, "art")
+
+	tests := []struct {
+		day  int
+		want string
+	}{
+		{1, "Day 1: Snow by j0hnny/Mistigris"}, // Manifest, group filled in from SAUCE
+		{2, "Day 2: Humbug! by Nitron"},        // SAUCE only
+		{3, "Day 3: Sleigh by LDA/MiSTiGRiS"},  // Manifest only
+		{4, ""},                                // Nothing known
+	}
+	for _, tt := range tests {
+		if got := m.Credit(2025, tt.day); got != tt.want {
+			t.Errorf("Credit(%d) = %q, want %q", tt.day, got, tt.want)
+		}
+	}
+}
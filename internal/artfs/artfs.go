@@ -0,0 +1,129 @@
+package artfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Overlay is a layered fs.FS: files under the art prefix are looked up in a
+// sysop-supplied directory first and fall back to the base filesystem
+// (normally the embedded art). Directory listings are merged, so extra
+// years on disk show up next to the embedded ones.
+type Overlay struct {
+	upper  fs.FS  // On-disk art directory, mounted at prefix
+	prefix string // Path the directory is mounted at (e.g. "art")
+	base   fs.FS  // Fallback filesystem
+}
+
+// New mounts dir at prefix on top of base. An empty dir returns base unchanged.
+func New(base fs.FS, dir, prefix string) fs.FS {
+	if dir == "" {
+		return base
+	}
+	logrus.WithFields(logrus.Fields{
+		"dir":    dir,
+		"prefix": prefix,
+	}).Info("Overlaying on-disk art directory")
+	return NewOverlay(base, os.DirFS(dir), prefix)
+}
+
+// NewOverlay mounts upper at prefix on top of base
+func NewOverlay(base, upper fs.FS, prefix string) *Overlay {
+	return &Overlay{
+		upper:  upper,
+		prefix: strings.Trim(prefix, "/"),
+		base:   base,
+	}
+}
+
+// upperName maps a name to its path inside the upper directory.
+// Returns false for names outside the mount prefix.
+func (o *Overlay) upperName(name string) (string, bool) {
+	if name == o.prefix {
+		return ".", true
+	}
+	if rest := strings.TrimPrefix(name, o.prefix+"/"); rest != name {
+		return rest, true
+	}
+	return "", false
+}
+
+// Open implements fs.FS, preferring the upper directory
+func (o *Overlay) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if upperName, ok := o.upperName(name); ok {
+		file, err := o.upper.Open(upperName)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			logrus.WithError(err).WithField("file", name).Warn("Failed to open art from disk, using built-in copy")
+		}
+	}
+
+	return o.base.Open(name)
+}
+
+// Stat implements fs.StatFS
+func (o *Overlay) Stat(name string) (fs.FileInfo, error) {
+	if upperName, ok := o.upperName(name); ok {
+		if info, err := fs.Stat(o.upper, upperName); err == nil {
+			return info, nil
+		}
+	}
+	return fs.Stat(o.base, name)
+}
+
+// ReadFile implements fs.ReadFileFS
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	if upperName, ok := o.upperName(name); ok {
+		if data, err := fs.ReadFile(o.upper, upperName); err == nil {
+			return data, nil
+		}
+	}
+	return fs.ReadFile(o.base, name)
+}
+
+// ReadDir implements fs.ReadDirFS, merging both layers.
+// Entries from the upper directory replace same-named base entries.
+func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	merged := make(map[string]fs.DirEntry)
+
+	baseEntries, baseErr := fs.ReadDir(o.base, name)
+	for _, entry := range baseEntries {
+		merged[entry.Name()] = entry
+	}
+
+	upperFound := false
+	if upperName, ok := o.upperName(name); ok {
+		upperEntries, err := fs.ReadDir(o.upper, upperName)
+		if err == nil {
+			upperFound = true
+			for _, entry := range upperEntries {
+				merged[entry.Name()] = entry
+			}
+		}
+	}
+
+	if baseErr != nil && !upperFound {
+		return nil, baseErr
+	}
+
+	entries := make([]fs.DirEntry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
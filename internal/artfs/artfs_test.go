@@ -0,0 +1,77 @@
+package artfs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func testOverlay() *Overlay {
+	base := fstest.MapFS{
+		"art/2024/WELCOME.ANS":   {Data: []byte("embedded welcome")},
+		"art/2024/1_DEC24.ANS":   {Data: []byte("embedded day 1")},
+		"art/common/FOOTER.ANS":  {Data: []byte("embedded footer")},
+		"art/common/MISSING.ANS": {Data: []byte("embedded missing")},
+	}
+	upper := fstest.MapFS{
+		"2024/WELCOME.ANS":  {Data: []byte("board welcome")},
+		"2026/WELCOME.ANS":  {Data: []byte("local year")},
+		"common/FOOTER.ANS": {Data: []byte("board footer")},
+	}
+	return NewOverlay(base, upper, "art")
+}
+
+func TestOverlayReadFile(t *testing.T) {
+	o := testOverlay()
+
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{"art/2024/WELCOME.ANS", "board welcome"},
+		{"art/2024/1_DEC24.ANS", "embedded day 1"},
+		{"art/common/FOOTER.ANS", "board footer"},
+		{"art/common/MISSING.ANS", "embedded missing"},
+		{"art/2026/WELCOME.ANS", "local year"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := fs.ReadFile(o, tc.name)
+			if err != nil {
+				t.Fatalf("ReadFile(%s) error: %v", tc.name, err)
+			}
+			if string(data) != tc.want {
+				t.Errorf("ReadFile(%s) = %q, want %q", tc.name, data, tc.want)
+			}
+		})
+	}
+
+	if _, err := fs.ReadFile(o, "art/2024/NOPE.ANS"); err == nil {
+		t.Error("expected error for file missing from both layers")
+	}
+}
+
+func TestOverlayReadDirMerges(t *testing.T) {
+	o := testOverlay()
+
+	entries, err := fs.ReadDir(o, "art")
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"2024", "2026", "common"}
+	if len(names) != len(want) {
+		t.Fatalf("ReadDir(art) = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("ReadDir(art) = %v, want %v", names, want)
+			break
+		}
+	}
+}
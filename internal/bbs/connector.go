@@ -61,9 +61,9 @@ func NewBBSConnection(dropfilePath string) (*BBSConnection, error) {
 	// Detect connection type based on platform
 	if runtime.GOOS == "windows" {
 		// Parse dropfile to determine connection type
-		door32Info, err := ParseDoor32(dropfilePath)
+		door32Info, err := ParseDropfile(dropfilePath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse door32.sys: %w", err)
+			return nil, fmt.Errorf("failed to parse dropfile: %w", err)
 		}
 
 		if door32Info.LineType == 2 {
@@ -128,7 +128,7 @@ func NewBBSConnection(dropfilePath string) (*BBSConnection, error) {
 		conn.stdinReader = bufio.NewReader(os.Stdin)
 		conn.stdoutWriter = bufio.NewWriter(os.Stdout)
 		conn.isConnected = true
-		logrus.Info("Using STDIN/STDOUT for Linux BBS (door32.sys parsed for user info only)")
+		logrus.Info("Using STDIN/STDOUT for Linux BBS (dropfile parsed for user info only)")
 	}
 
 	return conn, nil
@@ -163,60 +163,9 @@ func ParseDoor32(dropfilePath string) (*Door32Info, error) {
 		}).Debug("door32.sys line")
 	}
 
-	if len(lines) < 11 {
-		return nil, fmt.Errorf("door32.sys file is incomplete, expected 11 lines per spec, got %d", len(lines))
-	}
-
-	info := &Door32Info{}
-
-	// Parse according to official Door32 specification:
-	// Line 1 : Comm type (0=local, 1=serial, 2=telnet)
-	if info.LineType, err = strconv.Atoi(lines[0]); err != nil {
-		return nil, fmt.Errorf("invalid comm type (line 1): %s", lines[0])
-	}
-
-	// Line 2 : Comm or socket handle
-	if info.SocketHandle, err = strconv.Atoi(lines[1]); err != nil {
-		return nil, fmt.Errorf("invalid socket handle (line 2): %s", lines[1])
-	}
-
-	// Line 3 : Baud rate (we don't use this for socket connections)
-	// Line 4 : BBSID (software name and version)
-	info.BBSName = lines[3]
-
-	// Line 5 : User record position (1-based) - we don't use this
-	// Line 6 : User's real name
-	realName := lines[5]
-	nameParts := strings.Fields(realName)
-	if len(nameParts) >= 2 {
-		info.FirstName = nameParts[0]
-		info.LastName = strings.Join(nameParts[1:], " ")
-	} else {
-		info.FirstName = realName
-		info.LastName = ""
-	}
-
-	// Line 7 : User's handle/alias
-	info.Alias = lines[6]
-
-	// Line 8 : User's security level
-	if info.SecurityLevel, err = strconv.Atoi(lines[7]); err != nil {
-		return nil, fmt.Errorf("invalid security level (line 8): %s", lines[7])
-	}
-
-	// Line 9 : User's time left (in minutes)
-	if info.TimeLeft, err = strconv.Atoi(lines[8]); err != nil {
-		return nil, fmt.Errorf("invalid time left (line 9): %s", lines[8])
-	}
-
-	// Line 10: Emulation (0=Ascii, 1=Ansi, 2=Avatar, 3=RIP, 4=Max Graphics)
-	if info.Emulation, err = strconv.Atoi(lines[9]); err != nil {
-		return nil, fmt.Errorf("invalid emulation (line 10): %s", lines[9])
-	}
-
-	// Line 11: Current node number
-	if info.NodeNumber, err = strconv.Atoi(lines[10]); err != nil {
-		return nil, fmt.Errorf("invalid node number (line 11): %s", lines[10])
+	info, err := parseDoor32Lines(lines)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse socket information if it's a telnet connection (Windows only)
@@ -251,12 +200,15 @@ type SocketInfo struct {
 }
 
 // Door32Info holds parsed information from door32.sys
+// (or any other supported dropfile, see ParseDropfile)
 type Door32Info struct {
+	Format        DropfileFormat
 	LineType      int
 	BBSName       string
 	FirstName     string
 	LastName      string
 	Alias         string
+	UserRecord    int
 	SecurityLevel int
 	TimeLeft      int
 	Emulation     int
@@ -0,0 +1,535 @@
+package bbs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DropfileFormat identifies the BBS dropfile layout
+type DropfileFormat int
+
+const (
+	FormatUnknown    DropfileFormat = iota
+	FormatDoor32                    // DOOR32.SYS (11 lines)
+	FormatDoorSys                   // DOOR.SYS (GAP, 52 lines)
+	FormatDorinfo                   // DORINFOx.DEF (RBBS/QuickBBS, 13 lines)
+	FormatChainTxt                  // CHAIN.TXT (WWIV)
+	FormatPCBoardSys                // PCBOARD.SYS (PCBoard 14.x, binary)
+	FormatXtrnDat                   // XTRN.DAT (Synchronet)
+)
+
+// String returns the conventional file name for the format
+func (f DropfileFormat) String() string {
+	switch f {
+	case FormatDoor32:
+		return "DOOR32.SYS"
+	case FormatDoorSys:
+		return "DOOR.SYS"
+	case FormatDorinfo:
+		return "DORINFO1.DEF"
+	case FormatChainTxt:
+		return "CHAIN.TXT"
+	case FormatPCBoardSys:
+		return "PCBOARD.SYS"
+	case FormatXtrnDat:
+		return "XTRN.DAT"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	dorinfoNamePattern = regexp.MustCompile(`^DORINFO([0-9A-Z]?)\.DEF$`)
+	comPortPattern     = regexp.MustCompile(`^COM\d+:?$`)
+)
+
+// ParseDropfile reads any supported dropfile and returns the common user information.
+// The format is chosen from the file name, or from the contents when the name is not a standard one.
+func ParseDropfile(dropfilePath string) (*Door32Info, error) {
+	data, err := os.ReadFile(dropfilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	format := DetectDropfileFormat(dropfilePath, data)
+	logrus.WithFields(logrus.Fields{
+		"path":   dropfilePath,
+		"format": format,
+	}).Info("Detected dropfile format")
+
+	var info *Door32Info
+	switch format {
+	case FormatDoor32:
+		// door32.sys may carry socket details, which need the file path on Windows
+		return ParseDoor32(dropfilePath)
+	case FormatDoorSys:
+		info, err = parseDoorSys(dropfileLines(data))
+	case FormatDorinfo:
+		info, err = parseDorinfo(dropfileLines(data), dorinfoNode(dropfilePath))
+	case FormatChainTxt:
+		info, err = parseChainTxt(dropfileLines(data))
+	case FormatPCBoardSys:
+		info, err = parsePCBoardSys(data)
+	case FormatXtrnDat:
+		info, err = parseXtrnDat(dropfileLines(data))
+	default:
+		return nil, fmt.Errorf("unrecognized dropfile format: %s", filepath.Base(dropfilePath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+
+	info.Format = format
+	logrus.WithFields(logrus.Fields{
+		"format":    format,
+		"bbsName":   info.BBSName,
+		"alias":     info.Alias,
+		"security":  info.SecurityLevel,
+		"timeLeft":  info.TimeLeft,
+		"emulation": info.Emulation,
+		"node":      info.NodeNumber,
+	}).Info("Parsed dropfile")
+
+	return info, nil
+}
+
+// DetectDropfileFormat works out a dropfile's format from its name, falling back to its contents
+func DetectDropfileFormat(name string, data []byte) DropfileFormat {
+	base := strings.ToUpper(filepath.Base(name))
+	switch {
+	case base == "DOOR32.SYS":
+		return FormatDoor32
+	case base == "DOOR.SYS":
+		return FormatDoorSys
+	case dorinfoNamePattern.MatchString(base):
+		return FormatDorinfo
+	case base == "CHAIN.TXT":
+		return FormatChainTxt
+	case base == "PCBOARD.SYS":
+		return FormatPCBoardSys
+	case base == "XTRN.DAT":
+		return FormatXtrnDat
+	}
+
+	// Unknown name - look at the contents
+	if isBinary(data) {
+		if len(data) >= pcboardMinSize {
+			return FormatPCBoardSys
+		}
+		return FormatUnknown
+	}
+
+	lines := dropfileLines(data)
+	switch {
+	case len(lines) >= 20 && comPortPattern.MatchString(strings.ToUpper(lines[0])):
+		return FormatDoorSys
+	case len(lines) >= 12 && comPortPattern.MatchString(strings.ToUpper(lines[3])) &&
+		strings.Contains(strings.ToUpper(lines[4]), "BAUD"):
+		return FormatDorinfo
+	case len(lines) >= 13 && isXtrnTerminal(lines[9]):
+		return FormatXtrnDat
+	case len(lines) >= 11 && isOneOf(lines[0], "0", "1", "2") && isInt(lines[1]) && isInt(lines[9]) && isInt(lines[10]) && len(lines) < 20:
+		return FormatDoor32
+	case len(lines) >= 22 && isInt(lines[0]) && isInt(lines[10]) && isOneOf(lines[13], "0", "1"):
+		return FormatChainTxt
+	}
+
+	return FormatUnknown
+}
+
+// parseDoor32Lines parses the 11 door32.sys lines (socket details are handled by ParseDoor32)
+func parseDoor32Lines(lines []string) (*Door32Info, error) {
+	if len(lines) < 11 {
+		return nil, fmt.Errorf("door32.sys file is incomplete, expected 11 lines per spec, got %d", len(lines))
+	}
+
+	info := &Door32Info{Format: FormatDoor32}
+	var err error
+
+	// Line 1 : Comm type (0=local, 1=serial, 2=telnet)
+	if info.LineType, err = strconv.Atoi(lines[0]); err != nil {
+		return nil, fmt.Errorf("invalid comm type (line 1): %s", lines[0])
+	}
+
+	// Line 2 : Comm or socket handle
+	if info.SocketHandle, err = strconv.Atoi(lines[1]); err != nil {
+		return nil, fmt.Errorf("invalid socket handle (line 2): %s", lines[1])
+	}
+
+	// Line 3 : Baud rate (we don't use this for socket connections)
+	// Line 4 : BBSID (software name and version)
+	info.BBSName = lines[3]
+
+	// Line 5 : User record position (1-based)
+	info.UserRecord = atoiDefault(lines[4], 0)
+
+	// Line 6 : User's real name
+	info.FirstName, info.LastName = splitName(lines[5])
+
+	// Line 7 : User's handle/alias
+	info.Alias = lines[6]
+
+	// Line 8 : User's security level
+	if info.SecurityLevel, err = strconv.Atoi(lines[7]); err != nil {
+		return nil, fmt.Errorf("invalid security level (line 8): %s", lines[7])
+	}
+
+	// Line 9 : User's time left (in minutes)
+	if info.TimeLeft, err = strconv.Atoi(lines[8]); err != nil {
+		return nil, fmt.Errorf("invalid time left (line 9): %s", lines[8])
+	}
+
+	// Line 10: Emulation (0=Ascii, 1=Ansi, 2=Avatar, 3=RIP, 4=Max Graphics)
+	if info.Emulation, err = strconv.Atoi(lines[9]); err != nil {
+		return nil, fmt.Errorf("invalid emulation (line 10): %s", lines[9])
+	}
+
+	// Line 11: Current node number
+	if info.NodeNumber, err = strconv.Atoi(lines[10]); err != nil {
+		return nil, fmt.Errorf("invalid node number (line 11): %s", lines[10])
+	}
+
+	return info, nil
+}
+
+// parseDoorSys parses a GAP-style DOOR.SYS file
+func parseDoorSys(lines []string) (*Door32Info, error) {
+	if len(lines) < 20 {
+		return nil, fmt.Errorf("expected at least 20 lines, got %d", len(lines))
+	}
+
+	info := &Door32Info{}
+
+	// Line 1: COM port ("COM0:" means local)
+	if strings.HasPrefix(strings.ToUpper(lines[0]), "COM0") {
+		info.LineType = 0
+	} else {
+		info.LineType = 1
+	}
+
+	// Line 4: Node number
+	info.NodeNumber = atoiDefault(lines[3], 1)
+
+	// Line 10: User's full name
+	info.FirstName, info.LastName = splitName(lines[9])
+	info.Alias = lines[9]
+
+	// Line 15: Security level
+	var err error
+	if info.SecurityLevel, err = strconv.Atoi(lines[14]); err != nil {
+		return nil, fmt.Errorf("invalid security level (line 15): %s", lines[14])
+	}
+
+	// Line 19: Minutes remaining
+	if info.TimeLeft, err = strconv.Atoi(lines[18]); err != nil {
+		return nil, fmt.Errorf("invalid time left (line 19): %s", lines[18])
+	}
+
+	// Line 20: Graphics mode (GR=ANSI, NG=ASCII, 7E=7-bit ASCII, RIP)
+	switch strings.ToUpper(lines[19]) {
+	case "GR":
+		info.Emulation = 1
+	case "RIP":
+		info.Emulation = 3
+	default:
+		info.Emulation = 0
+	}
+
+	// Line 26: User record number
+	if len(lines) >= 26 {
+		info.UserRecord = atoiDefault(lines[25], 0)
+	}
+
+	// Line 36: Alias (newer DOOR.SYS writers only)
+	if len(lines) >= 36 && lines[35] != "" {
+		info.Alias = lines[35]
+	}
+
+	return info, nil
+}
+
+// parseDorinfo parses an RBBS/QuickBBS DORINFOx.DEF file
+func parseDorinfo(lines []string, node int) (*Door32Info, error) {
+	if len(lines) < 12 {
+		return nil, fmt.Errorf("expected at least 12 lines, got %d", len(lines))
+	}
+
+	info := &Door32Info{NodeNumber: node}
+
+	// Line 1: BBS name
+	info.BBSName = lines[0]
+
+	// Line 4: COM port ("COM0" means local)
+	if strings.HasPrefix(strings.ToUpper(lines[3]), "COM0") {
+		info.LineType = 0
+	} else {
+		info.LineType = 1
+	}
+
+	// Lines 7-8: User's first and last name (boards with aliases put the alias here)
+	info.FirstName = lines[6]
+	info.LastName = lines[7]
+	info.Alias = strings.TrimSpace(lines[6] + " " + lines[7])
+
+	// Line 10: Graphics (0=ASCII, 1=ANSI, 2=Avatar)
+	info.Emulation = atoiDefault(lines[9], 1)
+	if info.Emulation < 0 || info.Emulation > 2 {
+		info.Emulation = 1
+	}
+
+	// Line 11: Security level
+	var err error
+	if info.SecurityLevel, err = strconv.Atoi(lines[10]); err != nil {
+		return nil, fmt.Errorf("invalid security level (line 11): %s", lines[10])
+	}
+
+	// Line 12: Minutes remaining
+	if info.TimeLeft, err = strconv.Atoi(lines[11]); err != nil {
+		return nil, fmt.Errorf("invalid time left (line 12): %s", lines[11])
+	}
+
+	return info, nil
+}
+
+// parseChainTxt parses a WWIV CHAIN.TXT file
+func parseChainTxt(lines []string) (*Door32Info, error) {
+	if len(lines) < 22 {
+		return nil, fmt.Errorf("expected at least 22 lines, got %d", len(lines))
+	}
+
+	info := &Door32Info{NodeNumber: 1}
+
+	// Line 1: User number, Line 2: alias, Line 3: real name
+	info.UserRecord = atoiDefault(lines[0], 0)
+	info.Alias = lines[1]
+	info.FirstName, info.LastName = splitName(lines[2])
+
+	// Line 11: Security level
+	var err error
+	if info.SecurityLevel, err = strconv.Atoi(lines[10]); err != nil {
+		return nil, fmt.Errorf("invalid security level (line 11): %s", lines[10])
+	}
+
+	// Line 14: ANSI (1/0)
+	if lines[13] == "1" {
+		info.Emulation = 1
+	}
+
+	// Line 15: Remote (1/0)
+	if lines[14] == "1" {
+		info.LineType = 1
+	}
+
+	// Line 16: Seconds remaining
+	seconds, err := strconv.ParseFloat(lines[15], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seconds remaining (line 16): %s", lines[15])
+	}
+	info.TimeLeft = int(seconds) / 60
+
+	// Line 22: System name
+	info.BBSName = lines[21]
+
+	// Line 32: Node number (WWIV 4.2x and later)
+	if len(lines) >= 32 {
+		info.NodeNumber = atoiDefault(lines[31], 1)
+	}
+
+	return info, nil
+}
+
+// PCBOARD.SYS (14.x) field offsets
+const (
+	pcboardGraphicsOffset  = 11  // 'Y' = ANSI, 'N' = no graphics, '7' = 7E1
+	pcboardRecordOffset    = 23  // uint16 user record number
+	pcboardFirstNameOffset = 25  // 15 chars
+	pcboardFullNameOffset  = 84  // 25 chars
+	pcboardMinutesOffset   = 109 // int16 minutes remaining
+	pcboardNodeOffset      = 111 // node number (' ' if not networked)
+	pcboardCommPortOffset  = 125 // '0' = local
+	pcboardMinSize         = 128
+	pcboardFirstNameLength = 15
+	pcboardFullNameLength  = 25
+)
+
+// parsePCBoardSys parses a binary PCBOARD.SYS file
+func parsePCBoardSys(data []byte) (*Door32Info, error) {
+	if len(data) < pcboardMinSize {
+		return nil, fmt.Errorf("expected at least %d bytes, got %d", pcboardMinSize, len(data))
+	}
+
+	info := &Door32Info{}
+
+	if data[pcboardGraphicsOffset] == 'Y' {
+		info.Emulation = 1
+	}
+
+	info.UserRecord = int(binary.LittleEndian.Uint16(data[pcboardRecordOffset:]))
+
+	fullName := fixedString(data[pcboardFullNameOffset : pcboardFullNameOffset+pcboardFullNameLength])
+	if fullName == "" {
+		fullName = fixedString(data[pcboardFirstNameOffset : pcboardFirstNameOffset+pcboardFirstNameLength])
+	}
+	info.FirstName, info.LastName = splitName(fullName)
+	info.Alias = fullName
+
+	info.TimeLeft = int(int16(binary.LittleEndian.Uint16(data[pcboardMinutesOffset:])))
+
+	// Node number is stored as a single character or binary value
+	switch node := data[pcboardNodeOffset]; {
+	case node == ' ' || node == 0:
+		info.NodeNumber = 1
+	case node >= '0' && node <= '9':
+		info.NodeNumber = int(node - '0')
+	default:
+		info.NodeNumber = int(node)
+	}
+
+	if port := data[pcboardCommPortOffset]; port != '0' && port != 0 {
+		info.LineType = 1
+	}
+
+	return info, nil
+}
+
+// parseXtrnDat parses a Synchronet XTRN.DAT file
+func parseXtrnDat(lines []string) (*Door32Info, error) {
+	if len(lines) < 13 {
+		return nil, fmt.Errorf("expected at least 13 lines, got %d", len(lines))
+	}
+
+	info := &Door32Info{}
+
+	// Line 1: User name, Line 2: system name
+	info.Alias = lines[0]
+	info.FirstName, info.LastName = splitName(lines[0])
+	info.BBSName = lines[1]
+
+	// Line 8: Node number
+	info.NodeNumber = atoiDefault(lines[7], 1)
+
+	// Line 9: Seconds remaining
+	seconds, err := strconv.Atoi(lines[8])
+	if err != nil {
+		return nil, fmt.Errorf("invalid seconds remaining (line 9): %s", lines[8])
+	}
+	info.TimeLeft = seconds / 60
+
+	// Line 10: Terminal type
+	if strings.EqualFold(lines[9], "ANSI") || strings.EqualFold(lines[9], "COLOR") {
+		info.Emulation = 1
+	}
+
+	// Line 13: Security level
+	if info.SecurityLevel, err = strconv.Atoi(lines[12]); err != nil {
+		return nil, fmt.Errorf("invalid security level (line 13): %s", lines[12])
+	}
+
+	// Line 17: User number
+	if len(lines) >= 17 {
+		info.UserRecord = atoiDefault(lines[16], 0)
+	}
+
+	// Line 19: COM port (0 = local/socket handled by Synchronet)
+	if len(lines) >= 19 && atoiDefault(lines[18], 0) != 0 {
+		info.LineType = 1
+	}
+
+	return info, nil
+}
+
+// dropfileLines splits dropfile text into trimmed lines, stopping at a DOS EOF marker
+func dropfileLines(data []byte) []string {
+	if idx := bytes.IndexByte(data, 0x1A); idx != -1 {
+		data = data[:idx]
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	// Drop the empty element left by a trailing newline
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// dorinfoNode returns the node number encoded in a DORINFOx.DEF name (DORINFO.DEF is node 1)
+func dorinfoNode(dropfilePath string) int {
+	matches := dorinfoNamePattern.FindStringSubmatch(strings.ToUpper(filepath.Base(dropfilePath)))
+	if len(matches) < 2 || matches[1] == "" {
+		return 1
+	}
+	ch := matches[1][0]
+	if ch >= '0' && ch <= '9' {
+		if ch == '0' {
+			return 10
+		}
+		return int(ch - '0')
+	}
+	// DORINFOA.DEF = node 10, DORINFOB.DEF = node 11, ...
+	return int(ch-'A') + 10
+}
+
+// splitName splits a full name into first and last parts
+func splitName(realName string) (string, string) {
+	nameParts := strings.Fields(realName)
+	if len(nameParts) >= 2 {
+		return nameParts[0], strings.Join(nameParts[1:], " ")
+	}
+	return realName, ""
+}
+
+// fixedString trims a space/NUL padded fixed-width field
+func fixedString(field []byte) string {
+	return strings.TrimSpace(string(bytes.TrimRight(field, "\x00")))
+}
+
+// isBinary reports whether data contains control bytes that never appear in text dropfiles
+func isBinary(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 && b != '\r' && b != '\n' && b != '\t' && b != 0x1A {
+			return true
+		}
+	}
+	return false
+}
+
+// isXtrnTerminal reports whether a line looks like Synchronet's terminal type field
+func isXtrnTerminal(line string) bool {
+	switch strings.ToUpper(line) {
+	case "ANSI", "MONO", "COLOR":
+		return true
+	}
+	return false
+}
+
+func isInt(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func isOneOf(s string, options ...string) bool {
+	for _, option := range options {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
+
+func atoiDefault(s string, def int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,246 @@
+package bbs
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// crlf joins lines the way DOS-era BBS software writes them
+func crlf(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+var door32Sample = crlf(
+	"2",           // Comm type
+	"1234",        // Socket handle
+	"38400",       // Baud
+	"Mystic 1.12", // BBSID
+	"42",          // User record
+	"Jane Doe",    // Real name
+	"SnowQueen",   // Alias
+	"100",         // Security
+	"55",          // Time left
+	"1",           // Emulation
+	"3",           // Node
+)
+
+var doorSysSample = func() []byte {
+	lines := make([]string, 52)
+	lines[0] = "COM0:"
+	lines[1] = "38400"
+	lines[2] = "8"
+	lines[3] = "4" // Node
+	lines[9] = "Jane Doe"
+	lines[14] = "110"  // Security
+	lines[17] = "3000" // Seconds remaining
+	lines[18] = "50"   // Minutes remaining
+	lines[19] = "GR"   // Graphics
+	lines[25] = "17"   // User record
+	lines[34] = "Sysop Sam"
+	lines[35] = "SnowQueen" // Alias
+	return crlf(lines...)
+}()
+
+var dorinfoSample = crlf(
+	"Frosty BBS",       // BBS name
+	"Sam",              // Sysop first
+	"Sysop",            // Sysop last
+	"COM1",             // COM port
+	"38400 BAUD,N,8,1", // Baud
+	"0",                // Networked
+	"SNOWQUEEN",        // User first name
+	"",                 // User last name
+	"North Pole",       // Location
+	"1",                // Graphics
+	"90",               // Security
+	"45",               // Minutes left
+	"-1",               // FOSSIL
+)
+
+var chainTxtSample = crlf(
+	"12",                 // User number
+	"SnowQueen",          // Alias
+	"Jane Doe",           // Real name
+	"",                   // Callsign
+	"30",                 // Age
+	"F",                  // Sex
+	"100",                // Gold
+	"12/01/25",           // Last logon
+	"80",                 // Columns
+	"25",                 // Lines
+	"80",                 // Security
+	"0",                  // Co-sysop
+	"0",                  // Sysop
+	"1",                  // ANSI
+	"1",                  // Remote
+	"2400.000",           // Seconds remaining
+	"C:\\WWIV\\GFILES\\", // Gfiles dir
+	"C:\\WWIV\\DATA\\",   // Data dir
+	"990101.LOG",         // Sysop log
+	"38400",              // Baud
+	"1",                  // COM port
+	"WWIV Winterland",    // System name
+	"Sysop Sam",          // Sysop name
+	"36000",              // Logon time
+	"120",                // Seconds online
+	"0",                  // KB uploaded
+	"0",                  // Files uploaded
+	"0",                  // KB downloaded
+	"0",                  // Files downloaded
+	"8N1",                // Parity
+	"38400",              // Port speed
+	"2",                  // Node
+)
+
+var xtrnDatSample = crlf(
+	"SnowQueen",   // User name
+	"Vertrauen",   // System name
+	"Digital Man", // Sysop
+	"The Guru",    // Guru
+	"/sbbs/data/", // Data dir
+	"/sbbs/ctrl/", // Ctrl dir
+	"8",           // Total nodes
+	"5",           // Node
+	"1800",        // Seconds left
+	"ANSI",        // Terminal
+	"24",          // Screen lines
+	"0",           // Credits
+	"60",          // Security level
+	"0",           // Transfer level
+	"01/01/90",    // Birthdate
+	"F",           // Sex
+	"7",           // User number
+	"555-1212",    // Phone
+	"0",           // COM port
+)
+
+func pcboardSample() []byte {
+	data := make([]byte, 128)
+	for i := range data {
+		data[i] = ' '
+	}
+	data[pcboardGraphicsOffset] = 'Y'
+	binary.LittleEndian.PutUint16(data[pcboardRecordOffset:], 21)
+	copy(data[pcboardFirstNameOffset:], "JANE")
+	copy(data[pcboardFullNameOffset:], "JANE DOE")
+	binary.LittleEndian.PutUint16(data[pcboardMinutesOffset:], 35)
+	data[pcboardNodeOffset] = 6
+	data[pcboardCommPortOffset] = '0'
+	return data
+}
+
+func TestParseDropfile(t *testing.T) {
+	testCases := []struct {
+		name      string
+		fileName  string
+		data      []byte
+		format    DropfileFormat
+		alias     string
+		bbsName   string
+		security  int
+		timeLeft  int
+		emulation int
+		node      int
+		record    int
+	}{
+		{"door32.sys", "door32.sys", door32Sample, FormatDoor32, "SnowQueen", "Mystic 1.12", 100, 55, 1, 3, 42},
+		{"DOOR.SYS", "DOOR.SYS", doorSysSample, FormatDoorSys, "SnowQueen", "", 110, 50, 1, 4, 17},
+		{"DORINFO1.DEF", "DORINFO1.DEF", dorinfoSample, FormatDorinfo, "SNOWQUEEN", "Frosty BBS", 90, 45, 1, 1, 0},
+		{"DORINFO node from name", "dorinfo3.def", dorinfoSample, FormatDorinfo, "SNOWQUEEN", "Frosty BBS", 90, 45, 1, 3, 0},
+		{"CHAIN.TXT", "CHAIN.TXT", chainTxtSample, FormatChainTxt, "SnowQueen", "WWIV Winterland", 80, 40, 1, 2, 12},
+		{"PCBOARD.SYS", "PCBOARD.SYS", pcboardSample(), FormatPCBoardSys, "JANE DOE", "", 0, 35, 1, 6, 21},
+		{"XTRN.DAT", "XTRN.DAT", xtrnDatSample, FormatXtrnDat, "SnowQueen", "Vertrauen", 60, 30, 1, 5, 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(path, tc.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := ParseDropfile(path)
+			if err != nil {
+				t.Fatalf("ParseDropfile() error: %v", err)
+			}
+
+			if info.Format != tc.format {
+				t.Errorf("Format = %v, want %v", info.Format, tc.format)
+			}
+			if info.Alias != tc.alias {
+				t.Errorf("Alias = %q, want %q", info.Alias, tc.alias)
+			}
+			if info.BBSName != tc.bbsName {
+				t.Errorf("BBSName = %q, want %q", info.BBSName, tc.bbsName)
+			}
+			if info.SecurityLevel != tc.security {
+				t.Errorf("SecurityLevel = %d, want %d", info.SecurityLevel, tc.security)
+			}
+			if info.TimeLeft != tc.timeLeft {
+				t.Errorf("TimeLeft = %d, want %d", info.TimeLeft, tc.timeLeft)
+			}
+			if info.Emulation != tc.emulation {
+				t.Errorf("Emulation = %d, want %d", info.Emulation, tc.emulation)
+			}
+			if info.NodeNumber != tc.node {
+				t.Errorf("NodeNumber = %d, want %d", info.NodeNumber, tc.node)
+			}
+			if info.UserRecord != tc.record {
+				t.Errorf("UserRecord = %d, want %d", info.UserRecord, tc.record)
+			}
+		})
+	}
+}
+
+func TestDetectDropfileFormatFromContents(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+		want DropfileFormat
+	}{
+		{"door32", door32Sample, FormatDoor32},
+		{"door.sys", doorSysSample, FormatDoorSys},
+		{"dorinfo", dorinfoSample, FormatDorinfo},
+		{"chain.txt", chainTxtSample, FormatChainTxt},
+		{"pcboard.sys", pcboardSample(), FormatPCBoardSys},
+		{"xtrn.dat", xtrnDatSample, FormatXtrnDat},
+		{"garbage", crlf("hello", "world"), FormatUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// A neutral file name forces content sniffing
+			if got := DetectDropfileFormat("/tmp/node1.drop", tc.data); got != tc.want {
+				t.Errorf("DetectDropfileFormat() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseDropfileErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		data     []byte
+	}{
+		{"short door32", "door32.sys", crlf("2", "1234", "38400")},
+		{"bad door.sys security", "DOOR.SYS", crlf(append([]string{"COM1:"}, make([]string, 19)...)...)},
+		{"short pcboard", "PCBOARD.SYS", []byte{1, 2, 3}},
+		{"unknown", "node1.drop", crlf("hello")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(path, tc.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParseDropfile(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path" // Use path instead of filepath for embedded FS (always forward slashes)
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ManifestFileName is the optional per-year manifest inside each year directory
+const ManifestFileName = "calendar.json"
+
+// Screen names understood by ScreenPath
+const (
+	ScreenWelcome  = "welcome"
+	ScreenComeback = "comeback"
+	ScreenGoodbye  = "goodbye"
+	ScreenInfo     = "info"
+	ScreenMembers  = "members"
+	ScreenTeaser   = "teaser" // Shown for a year none of whose days are open yet
+)
+
+// Default file names used when a year has no manifest (or the manifest leaves them out)
+var defaultScreenFiles = map[string]string{
+	ScreenWelcome:  "WELCOME.ANS",
+	ScreenComeback: "COMEBACK.ANS",
+	ScreenGoodbye:  "GOODBYE.ANS",
+	ScreenInfo:     "INFOFILE.ANS",
+	ScreenMembers:  "MEMBERS.ANS",
+	ScreenTeaser:   "TEASER.ANS",
+}
+
+// Manifest describes one year's calendar (calendar.json)
+type Manifest struct {
+	Days    []Day   `json:"days"`
+	Screens Screens `json:"screens"`
+	Key     string  `json:"key,omitempty"` // HashKey of the key callers need to open the year
+}
+
+// Day describes a single calendar day
+type Day struct {
+	Day    int        `json:"day"`
+	File   string     `json:"file"` // Relative to the year directory
+	Title  string     `json:"title,omitempty"`
+	Artist string     `json:"artist,omitempty"`
+	Group  string     `json:"group,omitempty"`
+	Unlock *time.Time `json:"unlock,omitempty"` // RFC 3339 timestamp, overrides the normal unlock rule
+}
+
+// Screens names the year's non-day screens (relative to the year directory)
+type Screens struct {
+	Welcome  string `json:"welcome,omitempty"`
+	Comeback string `json:"comeback,omitempty"`
+	Goodbye  string `json:"goodbye,omitempty"`
+	Info     string `json:"info,omitempty"`
+	Members  string `json:"members,omitempty"`
+	Teaser   string `json:"teaser,omitempty"`
+}
+
+// file returns the configured file for a screen name
+func (s Screens) file(screen string) string {
+	switch screen {
+	case ScreenWelcome:
+		return s.Welcome
+	case ScreenComeback:
+		return s.Comeback
+	case ScreenGoodbye:
+		return s.Goodbye
+	case ScreenInfo:
+		return s.Info
+	case ScreenMembers:
+		return s.Members
+	case ScreenTeaser:
+		return s.Teaser
+	}
+	return ""
+}
+
+// Day returns the manifest entry for a day
+func (m *Manifest) Day(day int) (Day, bool) {
+	for _, d := range m.Days {
+		if d.Day == day {
+			return d, true
+		}
+	}
+	return Day{}, false
+}
+
+// Resolver maps (year, day) and screen names to art paths.
+// It reads each year's calendar.json once and falls back to the
+// filename heuristics for years without one.
+type Resolver struct {
+	fs        fs.FS
+	baseDir   string
+	manifests map[int]*Manifest // nil entry = year has no (valid) manifest
+	rule      UnlockRule
+	keys      map[int]string // Key hashes from the config, by year
+	lock      sync.Mutex
+}
+
+// NewResolver creates a resolver over an art filesystem
+func NewResolver(artFS fs.FS, baseDir string) *Resolver {
+	return &Resolver{
+		fs:        artFS,
+		baseDir:   baseDir,
+		manifests: make(map[int]*Manifest),
+	}
+}
+
+// YearDir returns the directory for a year
+func (r *Resolver) YearDir(year int) string {
+	return path.Join(r.baseDir, strconv.Itoa(year))
+}
+
+// Manifest returns the parsed manifest for a year, or nil if it has none
+func (r *Resolver) Manifest(year int) *Manifest {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if m, loaded := r.manifests[year]; loaded {
+		return m
+	}
+
+	m, err := r.loadManifest(year)
+	if err != nil {
+		logrus.WithError(err).WithField("year", year).Warn("Ignoring invalid calendar manifest")
+	}
+	r.manifests[year] = m
+	return m
+}
+
+// Reload forgets all cached manifests so they are read again on next use
+func (r *Resolver) Reload() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.manifests = make(map[int]*Manifest)
+}
+
+// loadManifest reads and checks calendar.json for a year
+func (r *Resolver) loadManifest(year int) (*Manifest, error) {
+	data, err := fs.ReadFile(r.fs, path.Join(r.YearDir(year), ManifestFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFileName, err)
+	}
+
+	if m.Key != "" && !ValidKeyHash(m.Key) {
+		return nil, fmt.Errorf("key must be a hash from advent -hashkey, not the key itself")
+	}
+
+	seen := make(map[int]bool)
+	for _, d := range m.Days {
+		if d.Day < 1 || d.Day > 25 {
+			return nil, fmt.Errorf("day %d is out of range (1-25)", d.Day)
+		}
+		if seen[d.Day] {
+			return nil, fmt.Errorf("day %d is listed more than once", d.Day)
+		}
+		if d.File == "" {
+			return nil, fmt.Errorf("day %d has no file", d.Day)
+		}
+		seen[d.Day] = true
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"year": year,
+		"days": len(m.Days),
+	}).Debug("Loaded calendar manifest")
+
+	return &m, nil
+}
+
+// DayInfo returns the manifest entry for a day, if the year has one
+func (r *Resolver) DayInfo(year, day int) (Day, bool) {
+	m := r.Manifest(year)
+	if m == nil {
+		return Day{}, false
+	}
+	return m.Day(day)
+}
+
+// DayPath returns the path to a day's art file
+func (r *Resolver) DayPath(year, day int) string {
+	yearDir := r.YearDir(year)
+
+	if d, ok := r.DayInfo(year, day); ok {
+		return path.Join(yearDir, d.File)
+	}
+
+	// No manifest entry - try both zero-padded (01_DEC25.ANS) and single-digit (1_DEC25.ANS) formats
+	yearSuffix := strconv.Itoa(year)[2:]
+	var primaryFileName, fallbackFileName string
+
+	// For 2025 and later, try zero-padded format first
+	if year >= 2025 {
+		// Zero-padded format (e.g., 01_DEC25.ANS)
+		primaryFileName = fmt.Sprintf("%02d_DEC%s.ANS", day, yearSuffix)
+		// Single-digit format as fallback (e.g., 1_DEC25.ANS)
+		fallbackFileName = fmt.Sprintf("%d_DEC%s.ANS", day, yearSuffix)
+	} else {
+		// For 2024 and earlier, try single-digit format first
+		primaryFileName = fmt.Sprintf("%d_DEC%s.ANS", day, yearSuffix)
+		// Zero-padded format as fallback
+		fallbackFileName = fmt.Sprintf("%02d_DEC%s.ANS", day, yearSuffix)
+	}
+
+	// Try primary format first
+	primaryPath := path.Join(yearDir, primaryFileName)
+	if _, err := fs.Stat(r.fs, primaryPath); err == nil {
+		return primaryPath
+	}
+
+	// Try fallback format if primary format doesn't exist
+	fallbackPath := path.Join(yearDir, fallbackFileName)
+	if _, err := fs.Stat(r.fs, fallbackPath); err == nil {
+		return fallbackPath
+	}
+
+	// If neither exists, return the primary format path
+	// This will eventually fall back to MISSING.ANS in the display engine
+	return primaryPath
+}
+
+// DayExists reports whether a day's art file is present
+func (r *Resolver) DayExists(year, day int) bool {
+	_, err := fs.Stat(r.fs, r.DayPath(year, day))
+	return err == nil
+}
+
+// ScreenPath returns the path to one of the year's non-day screens.
+// Info and members fall back to the copy in the art root when the year has none.
+func (r *Resolver) ScreenPath(year int, screen string) string {
+	fileName, known := defaultScreenFiles[screen]
+	if !known {
+		return ""
+	}
+	if m := r.Manifest(year); m != nil {
+		if f := m.Screens.file(screen); f != "" {
+			fileName = f
+		}
+	}
+
+	yearPath := path.Join(r.YearDir(year), fileName)
+
+	switch screen {
+	case ScreenInfo, ScreenMembers:
+		if _, err := fs.Stat(r.fs, yearPath); err == nil {
+			return yearPath
+		}
+		return path.Join(r.baseDir, defaultScreenFiles[screen])
+	case ScreenTeaser:
+		// Years without a teaser of their own use the common "not yet" screen
+		if _, err := fs.Stat(r.fs, yearPath); err == nil {
+			return yearPath
+		}
+		return path.Join(r.baseDir, "common", "NOTYET.ANS")
+	default:
+		return yearPath
+	}
+}
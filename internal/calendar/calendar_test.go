@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolverHeuristics(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"art/2024/1_DEC24.ANS":  {},
+		"art/2024/02_DEC24.ANS": {},
+		"art/2025/01_DEC25.ANS": {},
+		"art/2025/2_DEC25.ANS":  {},
+		"art/2025/INFOFILE.ANS": {},
+		"art/2025/TEASER.ANS":   {},
+		"art/MEMBERS.ANS":       {},
+	}
+	r := NewResolver(mockFS, "art")
+
+	testCases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"2024 single digit", r.DayPath(2024, 1), "art/2024/1_DEC24.ANS"},
+		{"2024 zero-padded fallback", r.DayPath(2024, 2), "art/2024/02_DEC24.ANS"},
+		{"2024 missing uses primary", r.DayPath(2024, 3), "art/2024/3_DEC24.ANS"},
+		{"2025 zero-padded", r.DayPath(2025, 1), "art/2025/01_DEC25.ANS"},
+		{"2025 single digit fallback", r.DayPath(2025, 2), "art/2025/2_DEC25.ANS"},
+		{"2025 missing uses primary", r.DayPath(2025, 3), "art/2025/03_DEC25.ANS"},
+		{"welcome", r.ScreenPath(2025, ScreenWelcome), "art/2025/WELCOME.ANS"},
+		{"year info", r.ScreenPath(2025, ScreenInfo), "art/2025/INFOFILE.ANS"},
+		{"root members fallback", r.ScreenPath(2025, ScreenMembers), "art/MEMBERS.ANS"},
+		{"year teaser", r.ScreenPath(2025, ScreenTeaser), "art/2025/TEASER.ANS"},
+		{"common teaser fallback", r.ScreenPath(2024, ScreenTeaser), "art/common/NOTYET.ANS"},
+		{"unknown screen", r.ScreenPath(2025, "bogus"), ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %q, want %q", tc.got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolverManifest(t *testing.T) {
+	manifest := `{
+		"days": [
+			{"day": 1, "file": "days/first.ans", "title": "Snow", "artist": "j0hnny", "group": "MiSTiGRiS"},
+			{"day": 12, "file": "twelve.ans", "unlock": "2025-12-11T18:00:00-05:00"}
+		],
+		"screens": {"welcome": "HELLO.ANS", "info": "ABOUT.ANS"}
+	}`
+	mockFS := fstest.MapFS{
+		"art/2025/calendar.json": {Data: []byte(manifest)},
+		"art/2025/02_DEC25.ANS":  {},
+		"art/2025/ABOUT.ANS":     {},
+	}
+	r := NewResolver(mockFS, "art")
+
+	if got := r.DayPath(2025, 1); got != "art/2025/days/first.ans" {
+		t.Errorf("DayPath(1) = %q", got)
+	}
+	// Days missing from the manifest use the naming heuristics
+	if got := r.DayPath(2025, 2); got != "art/2025/02_DEC25.ANS" {
+		t.Errorf("DayPath(2) = %q", got)
+	}
+	if got := r.ScreenPath(2025, ScreenWelcome); got != "art/2025/HELLO.ANS" {
+		t.Errorf("ScreenPath(welcome) = %q", got)
+	}
+	if got := r.ScreenPath(2025, ScreenInfo); got != "art/2025/ABOUT.ANS" {
+		t.Errorf("ScreenPath(info) = %q", got)
+	}
+	if got := r.ScreenPath(2025, ScreenGoodbye); got != "art/2025/GOODBYE.ANS" {
+		t.Errorf("ScreenPath(goodbye) = %q", got)
+	}
+
+	day, ok := r.DayInfo(2025, 1)
+	if !ok || day.Title != "Snow" || day.Artist != "j0hnny" || day.Group != "MiSTiGRiS" {
+		t.Errorf("DayInfo(1) = %+v, %v", day, ok)
+	}
+	day, ok = r.DayInfo(2025, 12)
+	if !ok || day.Unlock == nil || day.Unlock.UTC().Hour() != 23 {
+		t.Errorf("DayInfo(12) unlock = %+v", day.Unlock)
+	}
+}
+
+func TestResolverInvalidManifest(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"art/2025/calendar.json": {Data: []byte(`{"days": [{"day": 30, "file": "x.ans"}]}`)},
+		"art/2025/01_DEC25.ANS":  {},
+	}
+	r := NewResolver(mockFS, "art")
+
+	// An invalid manifest is ignored and the heuristics still work
+	if m := r.Manifest(2025); m != nil {
+		t.Errorf("expected invalid manifest to be ignored, got %+v", m)
+	}
+	if got := r.DayPath(2025, 1); got != "art/2025/01_DEC25.ANS" {
+		t.Errorf("DayPath(1) = %q", got)
+	}
+}
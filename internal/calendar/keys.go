@@ -0,0 +1,68 @@
+package calendar
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// KeyHashCost is the bcrypt cost of new key hashes: slow enough that guessing
+// short keys from a hash shipped in calendar.json takes real effort. Tests
+// lower it to bcrypt.MinCost.
+var KeyHashCost = 12
+
+// HashKey returns the value stored for a year's unlock key: a bcrypt hash of
+// the year and the key in upper case without surrounding spaces, so callers
+// can type it in any case and a hash can't be copied to another year.
+// From a shell: advent -hashkey 2023 SANTA
+func HashKey(year int, key string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(keyInput(year, key), KeyHashCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// keyInput is what a year's key hash is taken of
+func keyInput(year int, key string) []byte {
+	return []byte(strconv.Itoa(year) + ":" + strings.ToUpper(strings.TrimSpace(key)))
+}
+
+// ValidKeyHash reports whether s is a HashKey value
+func ValidKeyHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+// SetKeyHashes sets the unlock key hashes by year. They win over the keys in
+// the years' manifests.
+func (r *Resolver) SetKeyHashes(hashes map[int]string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.keys = hashes
+}
+
+// KeyHash returns the hash of the key that unlocks a year, or "" when the
+// year has no key
+func (r *Resolver) KeyHash(year int) string {
+	r.lock.Lock()
+	hash, ok := r.keys[year]
+	r.lock.Unlock()
+	if ok {
+		return hash
+	}
+	if m := r.Manifest(year); m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+// CheckKey reports whether key unlocks a year that has one
+func (r *Resolver) CheckKey(year int, key string) bool {
+	want := r.KeyHash(year)
+	if want == "" || strings.TrimSpace(key) == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(want), keyInput(year, key)) == nil
+}
@@ -0,0 +1,56 @@
+package calendar
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mustHashKey returns a quick HashKey(year, key), failing the test on error
+func mustHashKey(t *testing.T, year int, key string) string {
+	t.Helper()
+	KeyHashCost = bcrypt.MinCost
+	hash, err := HashKey(year, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestKeys(t *testing.T) {
+	manifest := `{"days": [], "key": "` + mustHashKey(t, 2023, "Frosty") + `"}`
+	r := NewResolver(fstest.MapFS{"art/2023/calendar.json": {Data: []byte(manifest)}}, "art")
+
+	// Salted, so the same key hashes differently each time
+	if a, b := mustHashKey(t, 2023, "santa"), mustHashKey(t, 2023, "santa"); a == b || !ValidKeyHash(a) {
+		t.Errorf("HashKey(santa) = %s and %s, want two different valid hashes", a, b)
+	}
+
+	if !r.CheckKey(2023, "FROSTY") || !r.CheckKey(2023, " frosty") {
+		t.Error("the manifest's key should unlock 2023 in any case")
+	}
+	if r.CheckKey(2023, "rudolph") || r.CheckKey(2023, "") {
+		t.Error("a wrong or empty key unlocked 2023")
+	}
+	if r.KeyHash(2024) != "" || r.CheckKey(2024, "frosty") {
+		t.Error("a year without a key has nothing to unlock")
+	}
+
+	// Keys from the config win over the manifest's
+	r.SetKeyHashes(map[int]string{2023: mustHashKey(t, 2023, "rudolph")})
+	if r.CheckKey(2023, "frosty") || !r.CheckKey(2023, "Rudolph") {
+		t.Error("the configured key should replace the manifest's")
+	}
+
+	// A hash only unlocks the year it was made for
+	r.SetKeyHashes(map[int]string{2023: mustHashKey(t, 2024, "rudolph")})
+	if r.CheckKey(2023, "rudolph") {
+		t.Error("a hash made for 2024 unlocked 2023")
+	}
+
+	bad := NewResolver(fstest.MapFS{"art/2023/calendar.json": {Data: []byte(`{"key": "santa"}`)}}, "art")
+	if bad.Manifest(2023) != nil {
+		t.Error("a manifest with a plain-text key should be rejected")
+	}
+}
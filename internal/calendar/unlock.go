@@ -0,0 +1,165 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// LastDay is the final day of the calendar
+const LastDay = 25
+
+// FirstYear and LastYear bound the year directories treated as calendars
+const (
+	FirstYear = 2000
+	LastYear  = 2099
+)
+
+// ValidYear reports whether year is in the range of calendar years
+func ValidYear(year int) bool {
+	return year >= FirstYear && year <= LastYear
+}
+
+// UnlockRule decides when days open: each day at the same time of day in
+// one time zone, unless the year's manifest gives that day its own unlock time
+type UnlockRule struct {
+	Location *time.Location // Nil means the host's local zone
+	Hour     int
+	Minute   int
+}
+
+// ParseUnlockRule builds a rule from an IANA zone name ("" for the host's
+// zone) and a 24-hour "HH:MM" time ("" for midnight)
+func ParseUnlockRule(zone, clock string) (UnlockRule, error) {
+	var rule UnlockRule
+	if zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return rule, fmt.Errorf("unknown time zone %q: %w", zone, err)
+		}
+		rule.Location = loc
+	}
+	if clock != "" {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return rule, fmt.Errorf("unlock time %q must be HH:MM (24-hour)", clock)
+		}
+		rule.Hour, rule.Minute = t.Hour(), t.Minute()
+	}
+	return rule, nil
+}
+
+// Zone returns the rule's time zone
+func (u UnlockRule) Zone() *time.Location {
+	if u.Location == nil {
+		return time.Local
+	}
+	return u.Location
+}
+
+// In returns t in the rule's time zone
+func (u UnlockRule) In(t time.Time) time.Time {
+	return t.In(u.Zone())
+}
+
+// SetUnlockRule changes when days open for every component sharing the resolver
+func (r *Resolver) SetUnlockRule(rule UnlockRule) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.rule = rule
+}
+
+// UnlockRule returns the rule days open by
+func (r *Resolver) UnlockRule() UnlockRule {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.rule
+}
+
+// UnlockTime returns when a day opens: the manifest's unlock time for that
+// day if it has one, otherwise the rule's time of day on December <day>
+func (r *Resolver) UnlockTime(year, day int) time.Time {
+	if m := r.Manifest(year); m != nil {
+		if d, ok := m.Day(day); ok && d.Unlock != nil {
+			return *d.Unlock
+		}
+	}
+	rule := r.UnlockRule()
+	return time.Date(year, time.December, day, rule.Hour, rule.Minute, 0, 0, rule.Zone())
+}
+
+// MaxDay returns the last day of a year open at now. Days open in order, so
+// a day held back by the manifest also holds back the days after it.
+func (r *Resolver) MaxDay(year int, now time.Time) int {
+	for day := 1; day <= LastDay; day++ {
+		if now.Before(r.UnlockTime(year, day)) {
+			return day - 1
+		}
+	}
+	return LastDay
+}
+
+// NextUnlock returns when the next day of a year opens after now, or false
+// once every day is open
+func (r *Resolver) NextUnlock(year int, now time.Time) (time.Time, bool) {
+	day := r.MaxDay(year, now)
+	if day >= LastDay {
+		return time.Time{}, false
+	}
+	return r.UnlockTime(year, day+1), true
+}
+
+// InSeason reports whether now is in December, in the rule's zone, and the
+// first day of that year's calendar has opened
+func (r *Resolver) InSeason(now time.Time) bool {
+	local := r.UnlockRule().In(now)
+	return local.Month() == time.December && r.MaxDay(local.Year(), now) >= 1
+}
+
+// SeasonStart returns when the first day of the coming season opens: this
+// year's December 1 (in the rule's zone) until it has opened, then next year's
+func (r *Resolver) SeasonStart(now time.Time) time.Time {
+	year := r.UnlockRule().In(now).Year()
+	start := r.UnlockTime(year, 1)
+	if !now.Before(start) {
+		start = r.UnlockTime(year+1, 1)
+	}
+	return start
+}
+
+// countdownUnits are the units Countdown counts in, largest first
+var countdownUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// Countdown describes a duration in its two largest units, rounded down,
+// such as "3 days, 4 hours" or "1 minute, 5 seconds"
+func Countdown(d time.Duration) string {
+	for i, unit := range countdownUnits {
+		if d < unit.size && i < len(countdownUnits)-1 {
+			continue
+		}
+		text := plural(int(d/unit.size), unit.name)
+		if i+1 < len(countdownUnits) {
+			next := countdownUnits[i+1]
+			if n := int(d % unit.size / next.size); n > 0 {
+				text += ", " + plural(n, next.name)
+			}
+		}
+		return text
+	}
+	return ""
+}
+
+// plural returns "1 day" or "3 days"
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestUnlockRule(t *testing.T) {
+	rule, err := ParseUnlockRule("America/New_York", "18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"days": [
+		{"day": 4, "file": "04.ans", "unlock": "2025-12-05T12:00:00-05:00"},
+		{"day": 12, "file": "12.ans", "unlock": "2025-12-11T18:00:00-05:00"}
+	]}`
+	r := NewResolver(fstest.MapFS{"art/2025/calendar.json": {Data: []byte(manifest)}}, "art")
+	r.SetUnlockRule(rule)
+
+	eastern := func(day, hour, min int) time.Time {
+		return time.Date(2025, time.December, day, hour, min, 0, 0, rule.Location)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"before the first evening", eastern(1, 17, 59), 0},
+		{"first evening reveal", eastern(1, 18, 0), 1},
+		{"same instant seen from UTC", eastern(2, 18, 0).UTC(), 2},
+		{"held-back day 4 holds back day 5", eastern(5, 11, 0), 3},
+		{"held-back day opens", eastern(5, 18, 0), 5},
+		{"early day 12 opens with day 11", eastern(11, 18, 0), 12},
+		{"after the last day", eastern(31, 0, 0), 25},
+	}
+	for _, tt := range tests {
+		if got := r.MaxDay(2025, tt.now); got != tt.want {
+			t.Errorf("%s: MaxDay() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if next, ok := r.NextUnlock(2025, eastern(6, 9, 0)); !ok || !next.Equal(eastern(6, 18, 0)) {
+		t.Errorf("NextUnlock() = %v, %v, want Dec 6 18:00 Eastern", next, ok)
+	}
+	if !r.InSeason(eastern(1, 18, 0)) || r.InSeason(eastern(1, 17, 0)) {
+		t.Error("season should start with the first unlock")
+	}
+	if r.InSeason(time.Date(2026, time.January, 1, 12, 0, 0, 0, rule.Location)) {
+		t.Error("January should be out of season")
+	}
+}
+
+func TestParseUnlockRule(t *testing.T) {
+	if rule, err := ParseUnlockRule("", ""); err != nil || rule.Location != nil || rule.Hour != 0 {
+		t.Errorf("defaults = %+v, %v, want local midnight", rule, err)
+	}
+	for _, bad := range [][2]string{{"Mars/Olympus_Mons", ""}, {"", "6pm"}, {"", "25:00"}} {
+		if _, err := ParseUnlockRule(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseUnlockRule(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
+
+func TestSeasonStart(t *testing.T) {
+	rule, _ := ParseUnlockRule("America/New_York", "18:00")
+	r := NewResolver(fstest.MapFS{}, "art")
+	r.SetUnlockRule(rule)
+	eastern := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, rule.Location)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"late November", eastern(2025, 11, 27, 14), eastern(2025, 12, 1, 18)},
+		{"December 1 before the reveal", eastern(2025, 12, 1, 9), eastern(2025, 12, 1, 18)},
+		{"after the season", eastern(2025, 12, 28, 9), eastern(2026, 12, 1, 18)},
+		{"new year in UTC, still December 31 in New York", eastern(2025, 12, 31, 22).UTC(), eastern(2026, 12, 1, 18)},
+	}
+	for _, tt := range tests {
+		if got := r.SeasonStart(tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: SeasonStart() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{3*24*time.Hour + 4*time.Hour + 59*time.Minute, "3 days, 4 hours"},
+		{24 * time.Hour, "1 day"},
+		{time.Hour + time.Minute + 30*time.Second, "1 hour, 1 minute"},
+		{65 * time.Second, "1 minute, 5 seconds"},
+		{1500 * time.Millisecond, "1 second"},
+		{0, "0 seconds"},
+	}
+	for _, tt := range tests {
+		if got := Countdown(tt.d); got != tt.want {
+			t.Errorf("Countdown(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package clock lets the door run on a simulated time (-debug-date) and
+// tests pin the time, instead of reading time.Now directly.
+package clock
+
+import "time"
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the real clock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed is a clock stopped at one instant
+type Fixed time.Time
+
+// Now returns the fixed instant
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
+
+// Simulated returns a clock that reads start now and runs at normal speed
+// from there, so a preview of "Dec 11 at 23:59" reaches midnight a minute later
+func Simulated(start time.Time) Clock {
+	return &simulated{start: start, began: time.Now()}
+}
+
+type simulated struct {
+	start time.Time
+	began time.Time // Real time the simulation started, with its monotonic reading
+}
+
+// Now returns the simulated time
+func (s *simulated) Now() time.Time {
+	return s.start.Add(time.Since(s.began))
+}
@@ -0,0 +1,20 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulated(t *testing.T) {
+	start := time.Date(2025, time.December, 11, 23, 59, 0, 0, time.UTC)
+	c := Simulated(start)
+	time.Sleep(10 * time.Millisecond)
+
+	now := c.Now()
+	if elapsed := now.Sub(start); elapsed < 10*time.Millisecond || elapsed > time.Second {
+		t.Errorf("simulated clock moved %v, want about 10ms", elapsed)
+	}
+	if got := Fixed(start).Now(); !got.Equal(start) {
+		t.Errorf("Fixed().Now() = %v, want %v", got, start)
+	}
+}
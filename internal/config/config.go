@@ -0,0 +1,464 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/validation"
+)
+
+// DefaultFileName is the config file looked up next to the executable
+// when no -config flag is given
+const DefaultFileName = "advent.json"
+
+// Config holds all sysop-tunable settings for the door
+type Config struct {
+	Display DisplayConfig `json:"display"`
+	Session SessionConfig `json:"session"`
+	Art     ArtConfig     `json:"art"`
+	Log     LogConfig     `json:"log"`
+	Server  ServerConfig  `json:"server"`
+	Data    DataConfig    `json:"data"`
+	Unlock  UnlockConfig  `json:"unlock"`
+	// Keys locks past years behind keys, by year: each value is the bcrypt
+	// hash printed by advent -hashkey YEAR KEY. They win over "key" in the
+	// years' calendar.json.
+	Keys   map[string]string `json:"keys"`
+	Access AccessConfig      `json:"access"`
+	// Screens are the sysop's own screens, each opened with its hotkey
+	Screens []ScreenConfig `json:"screens"`
+}
+
+// ScreenConfig is one of the sysop's own screens: art shown in a scrolling
+// viewer when its key is pressed on the welcome, comeback or day screens
+type ScreenConfig struct {
+	Name  string `json:"name"`  // Shown in the sysop menu's list of callers
+	Key   string `json:"key"`   // A single character, any case
+	Art   string `json:"art"`   // Path in the art directory, such as "common/RULES.ANS"
+	Level int    `json:"level"` // Security level needed to open it
+}
+
+// reservedKeys are the keys the door already uses, which screens can't take
+const reservedKeys = "CGIMNQY0123456789!<>,./"
+
+// AccessConfig sets the security levels (from the dropfile) callers need
+// for parts of the door
+type AccessConfig struct {
+	Sysop   int            `json:"sysop"`   // Level that opens the sysop menu with "!"; 0 turns it off
+	Years   map[string]int `json:"years"`   // Level needed to open a year
+	Screens map[string]int `json:"screens"` // Level needed for "info" or "members"
+}
+
+// restrictableScreens are the screens access.screens may name
+var restrictableScreens = map[string]bool{"info": true, "members": true}
+
+// DisplayConfig holds display settings (mode and size are decided at runtime)
+type DisplayConfig struct {
+	Theme    string `json:"theme"`
+	NoIce    bool   `json:"no_ice"`
+	NoDetect bool   `json:"no_detect"`
+	// CreditCorner is where the C key shows the art credit:
+	// top-left, top-right, bottom-left or bottom-right
+	CreditCorner string                    `json:"credit_corner"`
+	Scrolling    display.ScrollingConfig   `json:"scrolling"`
+	Columns      display.ColumnConfig      `json:"columns"`
+	Performance  display.PerformanceConfig `json:"performance"`
+}
+
+// SessionConfig holds session timeout settings
+type SessionConfig struct {
+	IdleTimeout Duration `json:"idle_timeout"`
+	MaxTimeout  Duration `json:"max_timeout"`
+}
+
+// ArtConfig describes where art files are loaded from
+type ArtConfig struct {
+	// Dir is an on-disk art directory layered over the embedded art.
+	// Files found there win; anything missing falls back to the built-in copy.
+	Dir string `json:"dir"`
+}
+
+// LogConfig holds logging settings
+type LogConfig struct {
+	Level string `json:"level"` // logrus level name (error, warn, info, debug...)
+	File  string `json:"file"`  // Log file path, empty for stderr
+}
+
+// ServerConfig holds settings for the built-in server mode (-serve)
+type ServerConfig struct {
+	MaxNodes    int      `json:"max_nodes"`    // Concurrent callers allowed
+	IdleTimeout Duration `json:"idle_timeout"` // Hang up on callers that send nothing for this long
+
+	// SSHHostKey is the host key file for -serve ssh; it is created on first
+	// use. Empty means advent_host_key next to the executable.
+	SSHHostKey string `json:"ssh_host_key"`
+	// SSHAuthorizedKeys is an OpenSSH authorized_keys file whose key comments
+	// are the login names the keys log in as. Empty allows anonymous guest
+	// logins.
+	SSHAuthorizedKeys string `json:"ssh_authorized_keys"`
+}
+
+// DataConfig describes where the door keeps what it remembers about callers
+type DataConfig struct {
+	// Dir holds the per-user data file shared by all nodes. Empty means a
+	// "data" directory next to the executable.
+	Dir string `json:"dir"`
+}
+
+// UnlockConfig decides when each day's door opens. A day can also be given
+// its own time with "unlock" in the year's calendar.json.
+type UnlockConfig struct {
+	TimeZone string `json:"timezone"` // IANA zone such as "America/New_York", empty for the host's zone
+	Time     string `json:"time"`     // 24-hour "HH:MM" each day opens at
+	// OffSeason is what callers get outside December: OffSeasonClosed shows
+	// NOTYET.ANS and exits (also when empty), OffSeasonArchive lets them
+	// browse past years
+	OffSeason string `json:"off_season"`
+}
+
+// Off-season modes for UnlockConfig.OffSeason
+const (
+	OffSeasonClosed  = "closed"
+	OffSeasonArchive = "archive"
+)
+
+// Duration is a time.Duration that reads and writes as a string like "5m"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string ("90s", "5m", "2h")
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Std returns the value as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// Default returns the built-in defaults (the values the door used before
+// config files existed)
+func Default() *Config {
+	return &Config{
+		Display: DisplayConfig{
+			Theme:        "classic",
+			CreditCorner: string(display.CornerBottomRight),
+			Scrolling: display.ScrollingConfig{
+				Enabled:           true,
+				Indicators:        false,
+				KeyboardShortcuts: true,
+			},
+			Columns: display.ColumnConfig{
+				Handle80ColumnIssue: true,
+				AutoDetectWidth:     true,
+			},
+			Performance: display.PerformanceConfig{
+				CacheEnabled: true,
+				CacheSizeMB:  50,
+				PreloadLines: 100,
+			},
+		},
+		Session: SessionConfig{
+			IdleTimeout: Duration(5 * time.Minute),
+			MaxTimeout:  Duration(120 * time.Minute),
+		},
+		Log: LogConfig{
+			Level: "error",
+		},
+		Server: ServerConfig{
+			MaxNodes:    8,
+			IdleTimeout: Duration(10 * time.Minute),
+		},
+		Unlock: UnlockConfig{
+			Time:      "00:00",
+			OffSeason: OffSeasonClosed,
+		},
+		Access: AccessConfig{
+			Sysop: 255,
+		},
+	}
+}
+
+// DefaultPath returns advent.json in the directory of the running executable
+func DefaultPath() string {
+	return besideExecutable(DefaultFileName)
+}
+
+// SSHHostKeyPath returns the configured SSH host key file or the default
+func (c *Config) SSHHostKeyPath() string {
+	if c.Server.SSHHostKey != "" {
+		return c.Server.SSHHostKey
+	}
+	return besideExecutable("advent_host_key")
+}
+
+// DataDir returns the configured data directory or the default
+func (c *Config) DataDir() string {
+	if c.Data.Dir != "" {
+		return c.Data.Dir
+	}
+	return besideExecutable("data")
+}
+
+// UnlockRule returns the configured unlock rule (local midnight if the
+// settings are invalid; Validate reports those)
+func (c *Config) UnlockRule() calendar.UnlockRule {
+	rule, err := calendar.ParseUnlockRule(c.Unlock.TimeZone, c.Unlock.Time)
+	if err != nil {
+		return calendar.UnlockRule{}
+	}
+	return rule
+}
+
+// KeyHashes returns the configured key hashes by year
+func (c *Config) KeyHashes() map[int]string {
+	hashes := make(map[int]string, len(c.Keys))
+	for year, hash := range c.Keys {
+		if y, err := strconv.Atoi(year); err == nil {
+			hashes[y] = hash
+		}
+	}
+	return hashes
+}
+
+// AccessLevels returns the configured security levels
+func (c *Config) AccessLevels() validation.Access {
+	access := validation.Access{
+		Sysop:   c.Access.Sysop,
+		Years:   make(map[int]int, len(c.Access.Years)),
+		Screens: c.Access.Screens,
+	}
+	for year, level := range c.Access.Years {
+		if y, err := strconv.Atoi(year); err == nil {
+			access.Years[y] = level
+		}
+	}
+	return access
+}
+
+// Hotkey returns the key a screen is opened with, in upper case
+func (s ScreenConfig) Hotkey() rune {
+	if s.Key == "" {
+		return 0
+	}
+	return rune(strings.ToUpper(s.Key)[0])
+}
+
+// ArchiveOffSeason reports whether past years stay browsable outside December
+func (c *Config) ArchiveOffSeason() bool {
+	return c.Unlock.OffSeason == OffSeasonArchive
+}
+
+// besideExecutable returns a path in the directory of the running executable
+func besideExecutable(name string) string {
+	exe, err := os.Executable()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(filepath.Dir(exe), name)
+}
+
+// Load reads a config file on top of the defaults.
+// A missing file is only an error when required is true (the path was given explicitly).
+func Load(path string, required bool) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			logrus.WithField("path", path).Debug("No config file found, using defaults")
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	logrus.WithField("path", path).Info("Loaded config file")
+	return cfg, nil
+}
+
+// Validate checks all values and returns every problem found
+func (c *Config) Validate() error {
+	var problems []string
+
+	if _, err := display.NewThemeManager().GetTheme(c.Display.Theme); err != nil {
+		problems = append(problems, fmt.Sprintf("display.theme: unknown theme %q (available: %s)",
+			c.Display.Theme, strings.Join(availableThemes(), ", ")))
+	}
+	if !display.Corner(c.Display.CreditCorner).Valid() {
+		problems = append(problems, fmt.Sprintf("display.credit_corner: unknown corner %q (use top-left, top-right, bottom-left or bottom-right)",
+			c.Display.CreditCorner))
+	}
+	if c.Display.Performance.CacheSizeMB < 0 {
+		problems = append(problems, "display.performance.cache_size_mb: must not be negative")
+	}
+	if c.Display.Performance.PreloadLines < 0 {
+		problems = append(problems, "display.performance.preload_lines: must not be negative")
+	}
+
+	if c.Session.IdleTimeout.Std() <= 0 {
+		problems = append(problems, "session.idle_timeout: must be greater than zero")
+	}
+	if c.Session.MaxTimeout.Std() <= 0 {
+		problems = append(problems, "session.max_timeout: must be greater than zero")
+	} else if c.Session.MaxTimeout.Std() < c.Session.IdleTimeout.Std() {
+		problems = append(problems, "session.max_timeout: must not be shorter than session.idle_timeout")
+	}
+
+	if c.Art.Dir != "" {
+		if info, err := os.Stat(c.Art.Dir); err != nil {
+			problems = append(problems, fmt.Sprintf("art.dir: %v", err))
+		} else if !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("art.dir: %s is not a directory", c.Art.Dir))
+		}
+	}
+
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("log.level: %v", err))
+	}
+
+	if c.Server.MaxNodes < 1 {
+		problems = append(problems, "server.max_nodes: must be at least 1")
+	}
+	if c.Server.IdleTimeout.Std() < 0 {
+		problems = append(problems, "server.idle_timeout: must not be negative")
+	}
+
+	if c.Server.SSHAuthorizedKeys != "" {
+		if _, err := os.Stat(c.Server.SSHAuthorizedKeys); err != nil {
+			problems = append(problems, fmt.Sprintf("server.ssh_authorized_keys: %v", err))
+		}
+	}
+
+	if c.Data.Dir != "" {
+		// A missing directory is created on first use
+		if info, err := os.Stat(c.Data.Dir); err == nil && !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("data.dir: %s is not a directory", c.Data.Dir))
+		}
+	}
+
+	if _, err := calendar.ParseUnlockRule(c.Unlock.TimeZone, c.Unlock.Time); err != nil {
+		problems = append(problems, fmt.Sprintf("unlock: %v", err))
+	}
+	for year, hash := range c.Keys {
+		if _, err := strconv.Atoi(year); err != nil {
+			problems = append(problems, fmt.Sprintf("keys: %q is not a year", year))
+		} else if !calendar.ValidKeyHash(hash) {
+			problems = append(problems, fmt.Sprintf("keys.%s: must be a hash from advent -hashkey %s KEY, not the key itself", year, year))
+		}
+	}
+
+	if c.Access.Sysop < 0 {
+		problems = append(problems, "access.sysop: must not be negative")
+	}
+	for year, level := range c.Access.Years {
+		if _, err := strconv.Atoi(year); err != nil {
+			problems = append(problems, fmt.Sprintf("access.years: %q is not a year", year))
+		} else if level < 0 {
+			problems = append(problems, fmt.Sprintf("access.years.%s: must not be negative", year))
+		}
+	}
+	for screen, level := range c.Access.Screens {
+		if !restrictableScreens[screen] {
+			problems = append(problems, fmt.Sprintf("access.screens: unknown screen %q (use info or members)", screen))
+		} else if level < 0 {
+			problems = append(problems, fmt.Sprintf("access.screens.%s: must not be negative", screen))
+		}
+	}
+
+	keys := make(map[string]string, len(c.Screens))
+	names := make(map[string]bool, len(c.Screens))
+	for i, screen := range c.Screens {
+		field := fmt.Sprintf("screens[%d]", i)
+		if screen.Name == "" {
+			problems = append(problems, field+".name: must not be empty")
+		} else if names[screen.Name] {
+			problems = append(problems, fmt.Sprintf("%s.name: %q is used by another screen", field, screen.Name))
+		}
+		names[screen.Name] = true
+
+		key := strings.ToUpper(screen.Key)
+		switch {
+		case len(key) != 1 || key[0] <= ' ' || key[0] > '~':
+			problems = append(problems, fmt.Sprintf("%s.key: %q must be a single character", field, screen.Key))
+		case strings.Contains(reservedKeys, key):
+			problems = append(problems, fmt.Sprintf("%s.key: %q is already used by the door (%s)", field, screen.Key, reservedKeys))
+		case keys[key] != "":
+			problems = append(problems, fmt.Sprintf("%s.key: %q is already used by screen %q", field, screen.Key, keys[key]))
+		default:
+			keys[key] = screen.Name
+		}
+
+		if !fs.ValidPath(screen.Art) || screen.Art == "." {
+			problems = append(problems, fmt.Sprintf("%s.art: %q must be a path in the art directory", field, screen.Art))
+		}
+		if screen.Level < 0 {
+			problems = append(problems, field+".level: must not be negative")
+		}
+	}
+
+	switch c.Unlock.OffSeason {
+	case "", OffSeasonClosed, OffSeasonArchive:
+	default:
+		problems = append(problems, fmt.Sprintf("unlock.off_season: unknown mode %q (use %s or %s)",
+			c.Unlock.OffSeason, OffSeasonClosed, OffSeasonArchive))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// DisplayConfig builds the display engine configuration for the given mode and size
+func (c *Config) DisplayConfig(mode display.DisplayMode, width, height int) display.DisplayConfig {
+	return display.DisplayConfig{
+		Mode:         mode,
+		Width:        width,
+		Height:       height,
+		Theme:        c.Display.Theme,
+		Scrolling:    c.Display.Scrolling,
+		Columns:      c.Display.Columns,
+		Performance:  c.Display.Performance,
+		NoIce:        c.Display.NoIce,
+		CreditCorner: display.Corner(c.Display.CreditCorner),
+	}
+}
+
+// availableThemes lists the built-in theme names in a stable order
+func availableThemes() []string {
+	names := display.NewThemeManager().ListThemes()
+	sort.Strings(names)
+	return names
+}
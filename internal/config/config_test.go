@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/robbiew/advent/internal/calendar"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "advent.json")
+
+	// Missing optional file falls back to defaults
+	cfg, err := Load(path, false)
+	if err != nil {
+		t.Fatalf("Load(optional) returned error: %v", err)
+	}
+	if cfg.Display.Theme != "classic" || cfg.Session.IdleTimeout.Std() != 5*time.Minute {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+
+	// Missing explicit file is an error
+	if _, err := Load(path, true); err == nil {
+		t.Error("Load(required) on missing file should fail")
+	}
+}
+
+func TestLoadOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "advent.json")
+	data := `{
+		"display": {"theme": "winter", "performance": {"cache_size_mb": 10}},
+		"session": {"idle_timeout": "90s", "max_timeout": "1h"},
+		"log": {"level": "debug"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path, true)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	if cfg.Display.Theme != "winter" {
+		t.Errorf("theme = %q, want winter", cfg.Display.Theme)
+	}
+	if cfg.Display.Performance.CacheSizeMB != 10 {
+		t.Errorf("cache_size_mb = %d, want 10", cfg.Display.Performance.CacheSizeMB)
+	}
+	// Values not in the file keep their defaults
+	if !cfg.Display.Performance.CacheEnabled || cfg.Display.Performance.PreloadLines != 100 {
+		t.Errorf("performance defaults lost: %+v", cfg.Display.Performance)
+	}
+	if cfg.Session.IdleTimeout.Std() != 90*time.Second || cfg.Session.MaxTimeout.Std() != time.Hour {
+		t.Errorf("timeouts = %v/%v", cfg.Session.IdleTimeout.Std(), cfg.Session.MaxTimeout.Std())
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "advent.json")
+	if err := os.WriteFile(path, []byte(`{"display": {"colour": "red"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, true); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	calendar.KeyHashCost = bcrypt.MinCost
+	frosty, err := calendar.HashKey(2023, "frosty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"defaults are valid", func(c *Config) {}, ""},
+		{"unknown theme", func(c *Config) { c.Display.Theme = "disco" }, "display.theme"},
+		{"unknown corner", func(c *Config) { c.Display.CreditCorner = "middle" }, "credit_corner"},
+		{"negative cache", func(c *Config) { c.Display.Performance.CacheSizeMB = -1 }, "cache_size_mb"},
+		{"zero idle timeout", func(c *Config) { c.Session.IdleTimeout = 0 }, "session.idle_timeout"},
+		{"max shorter than idle", func(c *Config) { c.Session.MaxTimeout = Duration(time.Minute) }, "session.max_timeout"},
+		{"missing art dir", func(c *Config) { c.Art.Dir = "/does/not/exist" }, "art.dir"},
+		{"bad log level", func(c *Config) { c.Log.Level = "loud" }, "log.level"},
+		{"no server nodes", func(c *Config) { c.Server.MaxNodes = 0 }, "server.max_nodes"},
+		{"unknown time zone", func(c *Config) { c.Unlock.TimeZone = "Mars/Olympus_Mons" }, "unlock"},
+		{"bad unlock time", func(c *Config) { c.Unlock.Time = "6pm" }, "unlock"},
+		{"off-season archive", func(c *Config) { c.Unlock.OffSeason = OffSeasonArchive }, ""},
+		{"unknown off-season mode", func(c *Config) { c.Unlock.OffSeason = "open" }, "unlock.off_season"},
+		{"evening reveal", func(c *Config) { c.Unlock = UnlockConfig{TimeZone: "America/New_York", Time: "18:00"} }, ""},
+		{"key hash", func(c *Config) { c.Keys = map[string]string{"2023": frosty} }, ""},
+		{"plain-text key", func(c *Config) { c.Keys = map[string]string{"2023": "frosty"} }, "keys.2023"},
+		{"key for a non-year", func(c *Config) { c.Keys = map[string]string{"last": frosty} }, "keys"},
+		{"restricted year and screen", func(c *Config) {
+			c.Access = AccessConfig{Sysop: 90, Years: map[string]int{"2023": 20}, Screens: map[string]int{"members": 10}}
+		}, ""},
+		{"restricted unknown screen", func(c *Config) { c.Access.Screens = map[string]int{"day": 10} }, "access.screens"},
+		{"negative year level", func(c *Config) { c.Access.Years = map[string]int{"2023": -1} }, "access.years.2023"},
+		{"data dir is a file", func(c *Config) { c.Data.Dir = "config_test.go" }, "data.dir"},
+		{"missing data dir is created later", func(c *Config) { c.Data.Dir = "/does/not/exist" }, ""},
+		{"sysop screen", func(c *Config) {
+			c.Screens = []ScreenConfig{{Name: "rules", Key: "r", Art: "common/RULES.ANS", Level: 20}}
+		}, ""},
+		{"screen on a door key", func(c *Config) {
+			c.Screens = []ScreenConfig{{Name: "rules", Key: "q", Art: "common/RULES.ANS"}}
+		}, "screens[0].key"},
+		{"screens sharing a key", func(c *Config) {
+			c.Screens = []ScreenConfig{{Name: "rules", Key: "r", Art: "RULES.ANS"}, {Name: "raffle", Key: "R", Art: "RAFFLE.ANS"}}
+		}, "screens[1].key"},
+		{"screen art outside the art dir", func(c *Config) {
+			c.Screens = []ScreenConfig{{Name: "rules", Key: "r", Art: "../RULES.ANS"}}
+		}, "screens[0].art"},
+		{"unnamed screen", func(c *Config) { c.Screens = []ScreenConfig{{Key: "r", Art: "RULES.ANS"}} }, "screens[0].name"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Default()
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Validate() = %v, want error mentioning %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package display
+
+import (
+	"strings"
+
+	"github.com/robbiew/advent/internal/vscreen"
+)
+
+// morePrompt is shown between pages in ASCII mode
+const morePrompt = "-- More -- (Enter to continue, Q to stop)"
+
+// cp437ASCII maps every CP437 byte to the closest plain ASCII character
+var cp437ASCII = func() (table [256]byte) {
+	for i := 0x20; i < 0x7F; i++ {
+		table[i] = byte(i)
+	}
+	// Control-code glyphs: faces, suits, arrows...
+	copy(table[0x00:], " oo*****#ooo+***><|!PS_|^v><L-^v")
+	table[0x7F] = '^'
+	// Accented letters and currency
+	copy(table[0x80:], "CueaaaaceeeiiiAAEaAooouuyOUcLYPfaiounNao?--%%!<>")
+	// Shades, box drawing and half blocks
+	table[0xB0], table[0xB1], table[0xB2] = '.', ':', '%'
+	for i := 0xB3; i <= 0xDA; i++ {
+		table[i] = '+'
+	}
+	table[0xB3], table[0xBA] = '|', '|'
+	table[0xC4], table[0xCD] = '-', '='
+	copy(table[0xDB:], "#_||\"")
+	// Greek letters and math symbols
+	copy(table[0xE0:], "aBGpSsutFOOd8oen=+><||/~o..vn2# ")
+	return table
+}()
+
+// asciiLine returns row y of the screen as plain ASCII, cropped to cols
+func asciiLine(screen *vscreen.Screen, y, cols int) string {
+	text := []byte(screen.Text(y, cols))
+	for i, c := range text {
+		text[i] = cp437ASCII[c]
+	}
+	return string(text)
+}
+
+// renderASCII draws CP437 ANSI content and returns its plain ASCII lines
+func renderASCII(content []byte, width int) []string {
+	screen := vscreen.New(width)
+	screen.Write(content)
+	lines := make([]string, screen.Height())
+	for y := range lines {
+		lines[y] = asciiLine(screen, y, width)
+	}
+	return lines
+}
+
+// processASCII renders content to plain ASCII lines for Emulation=0 callers
+func (de *DisplayEngine) processASCII(content []byte) []string {
+	screen := de.newScreen(content)
+	lines := make([]string, screen.Height())
+	for y := range lines {
+		lines[y] = asciiLine(screen, y, de.lineWidth(screen, y))
+	}
+	return lines
+}
+
+// renderPaged prints lines a screen at a time, waiting at a "more" prompt
+// between pages. Without a more function everything is printed at once.
+func (de *DisplayEngine) renderPaged(lines []string) error {
+	de.scrollState.TotalLines = len(lines)
+	de.scrollState.CurrentLine = 0
+	de.scrollState.CanScrollUp = false
+	de.scrollState.CanScrollDown = false
+
+	pageSize := de.config.Height - 1 // Leave a row for the prompt
+	if pageSize < 1 {
+		pageSize = 1
+	}
+
+	for i, line := range lines {
+		if i > 0 && i%pageSize == 0 && de.moreFunc != nil {
+			de.output.Write([]byte("\r\n" + morePrompt))
+			de.flushOutput()
+			more := de.moreFunc()
+			// Blank out the prompt so the next page starts on a clean line
+			de.output.Write([]byte("\r" + strings.Repeat(" ", len(morePrompt)) + "\r"))
+			if !more {
+				break
+			}
+		} else if i > 0 {
+			de.output.Write([]byte("\r\n"))
+		}
+		de.output.Write([]byte(line))
+	}
+
+	de.flushOutput()
+	return nil
+}
+
+// SetMoreFunc sets the function called at the ASCII "more" prompt. It should
+// wait for a key and return false when the caller wants to stop the listing.
+func (de *DisplayEngine) SetMoreFunc(more func() bool) {
+	de.moreFunc = more
+}
+
+// isASCII reports whether the caller gets plain text without escape sequences
+func (de *DisplayEngine) isASCII() bool {
+	return de.config.Mode == ModeASCII
+}
@@ -0,0 +1,62 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRenderASCII(t *testing.T) {
+	content := "\x1b[2J\x1b[1;1H\x1b[1;31mHello\x1b[0m\r\n" +
+		"\x1b[3;5H\xc4\xcd\xb3\xdb\xb0\x10" +
+		"\x1b[1;3Hy" + // Overwrite earlier text
+		"\x1b[5;1H" + strings.Repeat("=", 80) + "x" + // Wraps onto line 6
+		"\x1a\x1b[9;1Hignored"
+
+	got := renderASCII([]byte(content), 80)
+	want := []string{
+		"Heylo",
+		"",
+		"    -=|#.>",
+		"",
+		strings.Repeat("=", 80),
+		"x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("renderASCII() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestASCIIPaging(t *testing.T) {
+	var art strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&art, "\x1b[32mline %d\r\n", i)
+	}
+	artFS := fstest.MapFS{"art/TEST.ANS": {Data: []byte(art.String())}}
+
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeASCII, Width: 80, Height: 5}, artFS)
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	prompts := 0
+	de.SetMoreFunc(func() bool {
+		prompts++
+		return prompts < 2 // Stop at the second prompt
+	})
+
+	if err := de.Display("art/TEST.ANS", User{}); err != nil {
+		t.Fatal(err)
+	}
+	if prompts != 2 {
+		t.Errorf("more prompt shown %d times, want 2", prompts)
+	}
+	if bytes.IndexByte(out.Bytes(), 0x1b) >= 0 {
+		t.Errorf("ASCII output contains escape sequences: %q", out.String())
+	}
+	if !strings.Contains(out.String(), "line 8") || strings.Contains(out.String(), "line 9") {
+		t.Errorf("output should stop after line 8: %q", out.String())
+	}
+}
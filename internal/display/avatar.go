@@ -0,0 +1,323 @@
+package display
+
+import (
+	"io"
+
+	"github.com/robbiew/advent/internal/vscreen"
+)
+
+// AVATAR/0+ control codes (FSC-0025 / FSC-0037)
+const (
+	avtClear  = 0x0C // ^L clear screen, attribute 3, cursor home
+	avtRepeat = 0x19 // ^Y <char> <count>
+	avtCmd    = 0x16 // ^V command prefix
+	avtAttr   = 0x01 // ^V^A <attr>
+	avtBlink  = 0x02 // ^V^B blink on
+	avtUp     = 0x03 // ^V^C
+	avtDown   = 0x04 // ^V^D
+	avtLeft   = 0x05 // ^V^E
+	avtRight  = 0x06 // ^V^F
+	avtClrEol = 0x07 // ^V^G clear to end of line
+	avtGoto   = 0x08 // ^V^H <row> <col>
+
+	avtClearAttr = 3    // Attribute a terminal falls back to after ^L
+	avtMinRun    = 4    // Shorter runs are cheaper sent as-is
+	avtMaxRun    = 0xFF // Largest count ^Y can carry
+)
+
+// ansiToPC maps ANSI color numbers (red=1, green=2...) to PC attribute order (blue=1, green=2...)
+var ansiToPC = [8]byte{0, 4, 2, 6, 1, 5, 3, 7}
+
+// AvatarWriter translates the ANSI stream written by the display engine into
+// AVATAR/0+ codes for callers whose door32.sys reports emulation 2. Colors
+// become ^V^A attributes, cursor movement becomes ^V^H, runs of one character
+// are sent as ^Y repeats and a full clear becomes ^L.
+type AvatarWriter struct {
+	w     io.Writer
+	width int
+
+	attr    byte // Attribute built up from SGR codes
+	reverse bool
+	sent    int // Attribute the terminal has, -1 when unknown
+
+	x, y           int
+	savedX, savedY int
+	known          bool // Position is known (after a clear or goto)
+	wrapNeeded     bool
+
+	esc     []byte // Partial escape sequence carried between writes
+	runChar byte
+	runLen  int
+	out     []byte
+}
+
+// NewAvatarWriter creates a translator writing to w for a terminal of the given width
+func NewAvatarWriter(w io.Writer, width int) *AvatarWriter {
+	if width <= 0 {
+		width = 80
+	}
+	return &AvatarWriter{w: w, width: width, attr: 7, sent: -1}
+}
+
+// Write translates p and writes the result to the underlying writer
+func (aw *AvatarWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		aw.writeByte(b)
+	}
+	aw.flushRun()
+
+	if len(aw.out) == 0 {
+		return len(p), nil
+	}
+	_, err := aw.w.Write(aw.out)
+	aw.out = aw.out[:0]
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush flushes the underlying writer if it buffers
+func (aw *AvatarWriter) Flush() error {
+	if flusher, ok := aw.w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// writeByte feeds one byte of ANSI output through the translator
+func (aw *AvatarWriter) writeByte(b byte) {
+	if len(aw.esc) > 0 {
+		aw.esc = append(aw.esc, b)
+		switch {
+		case len(aw.esc) == 2 && b != '[':
+			aw.escape(b)
+			aw.esc = aw.esc[:0]
+		case len(aw.esc) > 2 && b >= 0x40 && b <= 0x7E:
+			aw.csi(string(aw.esc[2:len(aw.esc)-1]), b)
+			aw.esc = aw.esc[:0]
+		}
+		return
+	}
+
+	switch b {
+	case 0x1B:
+		aw.esc = append(aw.esc, b)
+	case '\r':
+		aw.control(b)
+		aw.x = 0
+	case '\n':
+		aw.control(b)
+		aw.y++
+	case '\b':
+		aw.control(b)
+		if aw.x > 0 {
+			aw.x--
+		}
+	case 0x07, '\t':
+		aw.control(b)
+	default:
+		aw.text(b)
+	}
+}
+
+// control sends a control character that moves the cursor or rings the bell
+func (aw *AvatarWriter) control(b byte) {
+	aw.flushRun()
+	aw.wrapNeeded = false
+	aw.out = append(aw.out, b)
+}
+
+// text queues a printable character, coalescing runs of the same character
+func (aw *AvatarWriter) text(b byte) {
+	if aw.sent != aw.currentAttr() {
+		aw.flushRun()
+		aw.syncAttr()
+	}
+	if aw.runLen > 0 && aw.runChar == b && aw.runLen < avtMaxRun {
+		aw.runLen++
+	} else {
+		aw.flushRun()
+		aw.runChar, aw.runLen = b, 1
+	}
+
+	// Track the cursor, wrapping at the right edge like the terminal does
+	if aw.wrapNeeded {
+		aw.x, aw.y = 0, aw.y+1
+		aw.wrapNeeded = false
+	}
+	if aw.x == aw.width-1 {
+		aw.wrapNeeded = true
+	} else {
+		aw.x++
+	}
+}
+
+// flushRun sends the pending run of characters
+func (aw *AvatarWriter) flushRun() {
+	if aw.runLen == 0 {
+		return
+	}
+	// CP437 glyphs that share a byte with AVATAR codes are sent through ^Y,
+	// whose character argument is always taken literally
+	literal := aw.runChar == avtClear || aw.runChar == avtCmd || aw.runChar == avtRepeat
+	if aw.runLen >= avtMinRun || literal {
+		aw.out = append(aw.out, avtRepeat, aw.runChar, byte(aw.runLen))
+	} else {
+		for i := 0; i < aw.runLen; i++ {
+			aw.out = append(aw.out, aw.runChar)
+		}
+	}
+	aw.runLen = 0
+}
+
+// currentAttr returns the PC attribute for the current SGR state
+func (aw *AvatarWriter) currentAttr() int {
+	attr := aw.attr
+	if aw.reverse {
+		attr = attr&0x88 | (attr&0x07)<<4 | (attr&0x70)>>4
+	}
+	return int(attr)
+}
+
+// syncAttr sends ^V^A (and ^V^B for blink) when the terminal's attribute is stale
+func (aw *AvatarWriter) syncAttr() {
+	attr := aw.currentAttr()
+	if aw.sent == attr {
+		return
+	}
+	aw.out = append(aw.out, avtCmd, avtAttr, byte(attr&0x7F))
+	if attr&0x80 != 0 {
+		aw.out = append(aw.out, avtCmd, avtBlink)
+	}
+	aw.sent = attr
+}
+
+// escape handles a two-byte ESC sequence
+func (aw *AvatarWriter) escape(b byte) {
+	switch b {
+	case '7':
+		aw.savedX, aw.savedY = aw.x, aw.y
+	case '8':
+		aw.moveTo(aw.savedX, aw.savedY)
+	}
+}
+
+// csi handles a control sequence with the given parameters and final byte
+func (aw *AvatarWriter) csi(params string, final byte) {
+	p := vscreen.ParseParams(params)
+	n := vscreen.Param(p, 0, 1)
+
+	switch final {
+	case 'm':
+		aw.sgr(p)
+	case 'H', 'f':
+		aw.moveTo(vscreen.Param(p, 1, 1)-1, vscreen.Param(p, 0, 1)-1)
+	case 'A':
+		aw.moveBy(0, -n, avtUp)
+	case 'B':
+		aw.moveBy(0, n, avtDown)
+	case 'C':
+		aw.moveBy(n, 0, avtRight)
+	case 'D':
+		aw.moveBy(-n, 0, avtLeft)
+	case 'J':
+		if vscreen.Param(p, 0, 0) == 2 {
+			aw.flushRun()
+			aw.out = append(aw.out, avtClear)
+			aw.sent = avtClearAttr
+			aw.x, aw.y, aw.known, aw.wrapNeeded = 0, 0, true, false
+		}
+	case 'K':
+		if vscreen.Param(p, 0, 0) == 0 {
+			aw.flushRun()
+			aw.syncAttr()
+			aw.out = append(aw.out, avtCmd, avtClrEol)
+		}
+	case 's':
+		aw.savedX, aw.savedY = aw.x, aw.y
+	case 'u':
+		aw.moveTo(aw.savedX, aw.savedY)
+	}
+	// Anything else (cursor visibility, blink/ICE modes...) has no AVATAR equivalent
+}
+
+// sgr applies SGR parameters to the attribute being built
+func (aw *AvatarWriter) sgr(p []int) {
+	if len(p) == 0 {
+		p = []int{0}
+	}
+	for _, code := range p {
+		switch {
+		case code == 0:
+			aw.attr, aw.reverse = 7, false
+		case code == 1:
+			aw.attr |= 0x08
+		case code == 22:
+			aw.attr &^= 0x08
+		case code == 5:
+			aw.attr |= 0x80
+		case code == 25:
+			aw.attr &^= 0x80
+		case code == 7:
+			aw.reverse = true
+		case code == 27:
+			aw.reverse = false
+		case code >= 30 && code <= 37:
+			aw.attr = aw.attr&^0x07 | ansiToPC[code-30]
+		case code == 39:
+			aw.attr = aw.attr&^0x07 | 7
+		case code >= 40 && code <= 47:
+			aw.attr = aw.attr&^0x70 | ansiToPC[code-40]<<4
+		case code == 49:
+			aw.attr &^= 0x70
+		case code >= 90 && code <= 97:
+			aw.attr = aw.attr&^0x07 | ansiToPC[code-90] | 0x08
+		case code >= 100 && code <= 107:
+			// High-intensity backgrounds share the blink bit (ICE colors)
+			aw.attr = aw.attr&^0x70 | ansiToPC[code-100]<<4 | 0x80
+		}
+	}
+}
+
+// moveTo sends ^V^H to an absolute, zero-based position
+func (aw *AvatarWriter) moveTo(x, y int) {
+	if x < 0 {
+		x = 0
+	}
+	if x >= aw.width {
+		x = aw.width - 1
+	}
+	if y < 0 {
+		y = 0
+	}
+	if y > 254 {
+		y = 254
+	}
+	aw.flushRun()
+	aw.out = append(aw.out, avtCmd, avtGoto, byte(y+1), byte(x+1))
+	aw.x, aw.y, aw.known, aw.wrapNeeded = x, y, true, false
+}
+
+// moveBy moves the cursor relatively, using a single goto once the position is known
+func (aw *AvatarWriter) moveBy(dx, dy int, code byte) {
+	if aw.known {
+		aw.moveTo(aw.x+dx, aw.y+dy)
+		return
+	}
+	aw.flushRun()
+	steps := dx + dy
+	if steps < 0 {
+		steps = -steps
+	}
+	for i := 0; i < steps; i++ {
+		aw.out = append(aw.out, avtCmd, code)
+	}
+	aw.x, aw.y, aw.wrapNeeded = aw.x+dx, aw.y+dy, false
+	if aw.x < 0 {
+		aw.x = 0
+	}
+	if aw.y < 0 {
+		aw.y = 0
+	}
+}
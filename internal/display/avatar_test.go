@@ -0,0 +1,41 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAvatarWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"clear and home", "\x1b[2J\x1b[1;1f", "\x0c\x16\x08\x01\x01"},
+		{"bright red on blue", "\x1b[1;31;44mA", "\x16\x01\x1cA"},
+		{"attribute sent once", "\x1b[32mab\x1b[32mc", "\x16\x01\x02abc"},
+		{"blink", "\x1b[0;5mX", "\x16\x01\x07\x16\x02X"},
+		{"run", "\x1b[0m\xdb\xdb\xdb\xdb\xdb", "\x16\x01\x07\x19\xdb\x05"},
+		{"short run", "\x1b[0m\xdb\xdb\xdb", "\x16\x01\x07\xdb\xdb\xdb"},
+		{"clashing glyph", "\x1b[0m\x0c", "\x16\x01\x07\x19\x0c\x01"},
+		{"relative move before clear", "\x1b[2C", "\x16\x06\x16\x06"},
+		{"relative move after clear", "\x1b[2J\x1b[3;5H\x1b[10C", "\x0c\x16\x08\x03\x05\x16\x08\x03\x0f"},
+		{"save and restore", "\x1b[2J\x1b[0mab\x1b7\r\n\x1b8", "\x0c\x16\x01\x07ab\r\n\x16\x08\x01\x03"},
+		{"clear to end of line", "\x1b[2J\x1b[K", "\x0c\x16\x01\x07\x16\x07"},
+		{"modes dropped", "\x1b[?25l\x1b[=0h", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			aw := NewAvatarWriter(&out, 80)
+			// Split the input to check sequences carried across writes
+			half := len(tt.in) / 2
+			aw.Write([]byte(tt.in[:half]))
+			aw.Write([]byte(tt.in[half:]))
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,183 +7,18 @@ import (
 	"io/fs"
 	"os"
 	"strings"
-	"unicode/utf8"
 
 	"golang.org/x/text/encoding/charmap"
-)
-
-// SetScrollState allows external code to set the scroll state for custom scrollable screens
-func (de *DisplayEngine) SetScrollState(currentLine, totalLines int) {
-	// Determine footer height by loading the footer file
-	footerHeight := 1 // Default to 1 row
-	footerLines, err := de.loadAndProcess("art/common/FOOTER.ANS")
-	if err == nil && len(footerLines) > 0 {
-		footerHeight = len(footerLines)
-		if footerHeight > 2 {
-			// Cap at 2 lines to avoid taking too much screen space
-			footerHeight = 2
-		}
-	}
 
-	de.scrollState.CurrentLine = currentLine
-	de.scrollState.TotalLines = totalLines
-	de.scrollState.VisibleLines = de.config.Height - footerHeight
-	de.updateScrollState()
-}
+	"github.com/robbiew/advent/internal/sauce"
+	"github.com/robbiew/advent/internal/vscreen"
+)
 
 // LoadAnsiLines loads and processes an ANSI file into lines (CP437/UTF-8 aware)
 func (de *DisplayEngine) LoadAnsiLines(filePath string) ([]string, error) {
 	return de.loadAndProcess(filePath)
 }
 
-// RenderScrollable renders a window of lines at the given scroll position
-func (de *DisplayEngine) RenderScrollable(lines []string, scrollPos int) error {
-	if len(lines) == 0 {
-		return nil
-	}
-	if scrollPos < 0 {
-		scrollPos = 0
-	}
-
-	// Determine footer height by loading the footer file
-	footerHeight := 1 // Default to 1 row
-	footerLines, err := de.loadAndProcess("art/common/FOOTER.ANS")
-	if err == nil && len(footerLines) > 0 {
-		footerHeight = len(footerLines)
-		if footerHeight > 2 {
-			// Cap at 2 lines to avoid taking too much screen space
-			footerHeight = 2
-		}
-	}
-
-	// Reserve space for footer
-	usableHeight := de.config.Height - footerHeight
-
-	maxStart := len(lines) - usableHeight
-	if scrollPos > maxStart {
-		scrollPos = maxStart
-		if scrollPos < 0 {
-			scrollPos = 0
-		}
-	}
-	de.ClearScreen()
-	end := scrollPos + usableHeight
-	if end > len(lines) {
-		end = len(lines)
-	}
-
-	// Apply 80-column handling for each visible line
-	visibleLines := lines[scrollPos:end]
-	if de.config.Columns.Handle80ColumnIssue && de.config.Width == 80 {
-		visibleLines = de.handle80ColumnIssue(visibleLines)
-	}
-
-	for i := 0; i < len(visibleLines); i++ {
-		isLast := i == len(visibleLines)-1
-		de.printLine(visibleLines[i], isLast)
-	}
-	// Draw menu bar at bottom
-	de.renderMenuBar()
-	de.flushOutput()
-	return nil
-}
-
-// RenderScrollableContentOnly renders only the content area without clearing screen or redrawing footer
-// This is used for efficient scrolling where the footer remains static
-func (de *DisplayEngine) RenderScrollableContentOnly(lines []string, scrollPos int) error {
-	if len(lines) == 0 {
-		return nil
-	}
-	if scrollPos < 0 {
-		scrollPos = 0
-	}
-
-	// Determine footer height by loading the footer file
-	footerHeight := 1 // Default to 1 row
-	footerLines, err := de.loadAndProcess("art/common/FOOTER.ANS")
-	if err == nil && len(footerLines) > 0 {
-		footerHeight = len(footerLines)
-		if footerHeight > 2 {
-			// Cap at 2 lines to avoid taking too much screen space
-			footerHeight = 2
-		}
-	}
-
-	// Reserve space for footer
-	usableHeight := de.config.Height - footerHeight
-
-	maxStart := len(lines) - usableHeight
-	if scrollPos > maxStart {
-		scrollPos = maxStart
-		if scrollPos < 0 {
-			scrollPos = 0
-		}
-	}
-
-	end := scrollPos + usableHeight
-	if end > len(lines) {
-		end = len(lines)
-	}
-
-	// Apply 80-column handling for each visible line
-	visibleLines := lines[scrollPos:end]
-	if de.config.Columns.Handle80ColumnIssue && de.config.Width == 80 {
-		visibleLines = de.handle80ColumnIssue(visibleLines)
-	}
-
-	// Move cursor to top-left and render each line at its specific position
-	// This avoids clearing the screen and preserves the footer
-	for i := 0; i < usableHeight; i++ {
-		// Position cursor at the start of line i+1 (1-indexed)
-		de.output.Write([]byte(fmt.Sprintf("\033[%d;1H", i+1)))
-
-		if i < len(visibleLines) {
-			// Print the line content
-			de.output.Write([]byte(visibleLines[i]))
-		}
-
-		// Clear to end of line to remove any leftover content
-		de.output.Write([]byte("\033[K"))
-	}
-
-	de.flushOutput()
-	return nil
-}
-
-// renderMenuBar draws the menu bar at the last row of the terminal
-func (de *DisplayEngine) renderMenuBar() {
-	// Load the FOOTER.ANS file
-	footerLines, err := de.loadAndProcess("art/common/FOOTER.ANS")
-	if err != nil {
-		// Fallback if FOOTER.ANS doesn't exist
-		return
-	}
-
-	if len(footerLines) == 0 {
-		return
-	}
-
-	// Get the actual footer height (number of lines in the footer)
-	footerHeight := len(footerLines)
-	if footerHeight > 2 {
-		// Cap at 2 lines to avoid taking too much screen space
-		footerHeight = 2
-	}
-
-	// Move cursor to appropriate row based on footer height and reset colors
-	startRow := de.config.Height - footerHeight + 1
-	de.output.Write([]byte(fmt.Sprintf("\033[%d;1H\033[0m", startRow)))
-
-	// Print the footer (up to 2 lines)
-	for i := 0; i < footerHeight && i < len(footerLines); i++ {
-		de.output.Write([]byte(footerLines[i]))
-		if i < footerHeight-1 {
-			// Add newline between footer lines, but not after the last one
-			de.output.Write([]byte("\r\n"))
-		}
-	}
-}
-
 type TeeWriter struct {
 	writers []io.Writer
 }
@@ -235,6 +70,26 @@ func (dmw *DualModeWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// UTF8Writer converts CP437 output to UTF-8 for terminals that expect Unicode
+// (the web terminal), the same conversion DualModeWriter applies for the console
+type UTF8Writer struct {
+	w io.Writer
+}
+
+// NewUTF8Writer wraps w with CP437 to UTF-8 conversion
+func NewUTF8Writer(w io.Writer) *UTF8Writer {
+	return &UTF8Writer{w: w}
+}
+
+// Write converts p to UTF-8 and writes it. CP437 is one byte per character,
+// so any split of the input converts cleanly.
+func (uw *UTF8Writer) Write(p []byte) (n int, err error) {
+	if _, err = uw.w.Write(convertCP437ToUTF8(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // convertCP437ToUTF8 converts CP437 encoded bytes to UTF-8
 func convertCP437ToUTF8(data []byte) []byte {
 	// Use the same charmap.CodePage437 decoder as in processCP437
@@ -249,14 +104,17 @@ func convertCP437ToUTF8(data []byte) []byte {
 
 // DisplayEngine implements the Displayer interface
 type DisplayEngine struct {
-	config         DisplayConfig
-	themeManager   *ThemeManager
-	scrollState    ScrollState
-	cache          map[string][]string
-	currentContent []string      // Store current content for scrolling re-renders
-	output         io.Writer     // Output destination (console, BBS, or both)
-	fs             fs.FS         // Embedded filesystem for art files
-	stdoutBuf      *bufio.Writer // Buffered writer for Windows console
+	config       DisplayConfig
+	themeManager *ThemeManager
+	scrollState  ScrollState
+	cache        map[string][]string
+	viewer       *Viewer       // Scrolls the art on screen when it's taller than the screen
+	output       io.Writer     // Output destination (console, BBS, or both)
+	fs           fs.FS         // Embedded filesystem for art files
+	stdoutBuf    *bufio.Writer // Buffered writer for Windows console
+	moreFunc     func() bool   // Waits at the ASCII "more" prompt
+	credit       string        // Art credit drawn over the current screen
+	notice       string        // Message for the caller drawn along the bottom row
 }
 
 // NewDisplayEngine creates a new display engine
@@ -264,7 +122,7 @@ func NewDisplayEngine(config DisplayConfig, embeddedFS fs.FS) *DisplayEngine {
 	// Use buffered writer for stdout to ensure proper flushing on Windows
 	writer := bufio.NewWriter(os.Stdout)
 
-	return &DisplayEngine{
+	de := &DisplayEngine{
 		config:       config,
 		themeManager: NewThemeManager(),
 		cache:        make(map[string][]string),
@@ -273,22 +131,31 @@ func NewDisplayEngine(config DisplayConfig, embeddedFS fs.FS) *DisplayEngine {
 			TotalLines:   0,
 			VisibleLines: config.Height,
 		},
-		output:    writer,
 		stdoutBuf: writer,
 		fs:        embeddedFS,
 	}
+	de.output = de.translate(writer)
+	return de
+}
+
+// translate wraps the caller's writer in the translator their emulation needs
+func (de *DisplayEngine) translate(w io.Writer) io.Writer {
+	if de.config.Mode == ModeAvatar {
+		return NewAvatarWriter(w, de.config.Width)
+	}
+	return w
 }
 
 // SetBBSConnection configures output to BBS connection only (no sysop console)
 func (de *DisplayEngine) SetBBSConnection(bbsConn io.Writer) {
 	if bbsConn != nil {
 		// Output only to BBS connection (user terminal)
-		de.output = bbsConn
+		de.output = de.translate(bbsConn)
 		de.stdoutBuf = nil // BBS connection doesn't use stdout buffer
 	} else {
 		// Fall back to console only with buffered writer
 		de.stdoutBuf = bufio.NewWriter(os.Stdout)
-		de.output = de.stdoutBuf
+		de.output = de.translate(de.stdoutBuf)
 	}
 }
 
@@ -318,8 +185,7 @@ func (de *DisplayEngine) flushOutput() {
 
 // DisplayWithOverlay displays the content of an ANSI file with optional overlay text
 func (de *DisplayEngine) DisplayWithOverlay(filePath string, user User, overlayText string) error {
-	de.output.Write([]byte(Reset)) // Reset text and background colors
-	de.flushOutput()               // Ensure reset is sent
+	de.ResetColors() // Reset text and background colors
 	de.ClearScreen()
 
 	// Load and process content
@@ -346,44 +212,205 @@ func (de *DisplayEngine) DisplayWithOverlay(filePath string, user User, overlayT
 		return fmt.Errorf("empty file")
 	}
 
-	// Handle scrolling if needed
+	// ASCII callers get the whole screen a page at a time
+	if de.isASCII() {
+		de.viewer = nil
+		err = de.renderPaged(content)
+		if overlayText != "" {
+			de.renderOverlayText(overlayText, CornerBottomRight, overlayColor)
+		}
+		de.renderOverlays()
+		de.flushOutput()
+		return err
+	}
+
+	// Art taller than the screen scrolls in a viewer
 	if len(content) > de.config.Height && de.config.Scrolling.Enabled {
-		de.currentContent = content // Store for scroll re-renders
-		return de.renderWithScrolling(content)
+		de.NewViewer(content, false).Draw()
+		return nil
 	}
 
 	// Normal rendering (content fits on screen or scrolling disabled)
-	de.currentContent = nil // Clear stored content
+	de.viewer = nil
 	err = de.renderNormal(content)
 
 	// Add overlay text if provided (bottom right corner)
 	if overlayText != "" {
-		de.renderOverlayText(overlayText)
+		de.renderOverlayText(overlayText, CornerBottomRight, overlayColor)
 	}
+	de.renderOverlays()
+	de.flushOutput()
 
 	return err
 }
 
-// renderOverlayText renders text at the bottom right corner of the screen
-func (de *DisplayEngine) renderOverlayText(text string) {
+// DisplayText shows lines of plain text generated by the door, such as the
+// sysop menu, in the theme's notice color. Long lines are cut at the screen edge.
+func (de *DisplayEngine) DisplayText(lines []string) {
+	de.ResetColors()
+	de.ClearScreen()
+	de.viewer = nil
+
+	color := de.themeColor("notice")
+	if de.isASCII() {
+		color = ""
+	}
+	for _, line := range lines {
+		if limit := de.usableWidth(); len(line) > limit && limit > 0 {
+			line = line[:limit]
+		}
+		de.output.Write([]byte(color + line + "\r\n"))
+	}
+	if color != "" {
+		de.output.Write([]byte(Reset))
+	}
+	de.renderOverlays()
+	de.flushOutput()
+}
+
+// ClearCache forgets loaded art so files changed on disk are read again
+func (de *DisplayEngine) ClearCache() {
+	de.cache = make(map[string][]string)
+}
+
+// overlayColor is bright white on black, used for the missing-file overlay
+const overlayColor = "\033[97;40m"
+
+// renderOverlayText renders text in a corner of the screen with the given color codes
+func (de *DisplayEngine) renderOverlayText(text string, corner Corner, color string) {
+	if de.isASCII() {
+		// No cursor positioning - put it on its own line instead
+		de.output.Write([]byte("\r\n" + text))
+		return
+	}
+
+	// Leave a column before text in the right corners
+	if limit := de.usableWidth() - 1; len(text) > limit && limit > 0 {
+		text = text[:limit]
+	}
+
 	// Save cursor position
 	de.output.Write([]byte("\0337")) // Save cursor position (ESC 7)
 
-	// Position cursor at bottom right
 	// Account for text length to position correctly
-	row := de.config.Height
-	col := de.config.Width - len(text)
-
+	row, col := 1, 1
+	if corner == CornerBottomLeft || corner == CornerBottomRight {
+		row = de.config.Height
+	}
+	if corner == CornerTopRight || corner == CornerBottomRight {
+		col = de.config.Width - len(text)
+	}
 	if col < 1 {
 		col = 1
 	}
 
-	// Move cursor and print text with bright white on black background
+	// Move cursor and print the text
 	de.output.Write([]byte(fmt.Sprintf("\033[%d;%dH", row, col)))
-	de.output.Write([]byte(fmt.Sprintf("\033[97;40m%s\033[0m", text))) // Bright white text on black background
+	de.output.Write([]byte(color + text + Reset))
 
 	// Restore cursor position
 	de.output.Write([]byte("\0338")) // Restore cursor position (ESC 8)
+}
+
+// SetCredit sets the credit line drawn over the following screens ("" for none)
+func (de *DisplayEngine) SetCredit(text string) {
+	de.credit = text
+}
+
+// SetNotice sets a message drawn along the bottom of the following screens ("" for none)
+func (de *DisplayEngine) SetNotice(text string) {
+	de.notice = text
+}
+
+// Notice returns the message drawn along the bottom of the current screen
+func (de *DisplayEngine) Notice() string {
+	return de.notice
+}
+
+// UpdateNotice replaces the notice on the current screen without redrawing
+// it, blanking whatever the old notice covered (for countdowns and prompts).
+// ASCII callers have it rewritten in place on its own line.
+func (de *DisplayEngine) UpdateNotice(text string) {
+	drawn := " " + text + " "
+	if pad := len(de.notice) - len(text); pad > 0 {
+		drawn += strings.Repeat(" ", pad)
+	}
+	if de.isASCII() {
+		de.notice = text
+		de.output.Write([]byte("\r" + drawn))
+		de.flushOutput()
+		return
+	}
+
+	// A credit on the bottom row makes way for the notice
+	was := de.creditCorner()
+	de.notice = text
+	moved := de.credit != "" && de.creditCorner() != was
+	if moved {
+		de.renderOverlayText(strings.Repeat(" ", len(de.credit)+2), was, Reset)
+	}
+	de.renderOverlayText(drawn, CornerBottomLeft, "\033[0;40m"+de.themeColor("notice"))
+	if moved {
+		de.renderCredit()
+	}
+	de.flushOutput()
+}
+
+// Toast draws a short message in the top-right corner over whatever is on
+// screen, without redrawing it. It stays until the next screen is drawn.
+func (de *DisplayEngine) Toast(text string) {
+	de.renderOverlayText(" "+text+" ", CornerTopRight, "\033[0;40m"+de.themeColor("notice"))
+	de.flushOutput()
+}
+
+// renderOverlays draws the notice and credit lines over the current screen
+func (de *DisplayEngine) renderOverlays() {
+	if de.notice != "" {
+		de.renderOverlayText(" "+de.notice+" ", CornerBottomLeft, "\033[0;40m"+de.themeColor("notice"))
+	}
+	de.renderCredit()
+}
+
+// usableWidth returns how many columns screens draw in. Never reaching the
+// last column keeps the bottom row from scrolling the screen.
+func (de *DisplayEngine) usableWidth() int {
+	return de.config.Width - 1
+}
+
+// themeColor returns a color from the configured theme
+func (de *DisplayEngine) themeColor(name string) string {
+	theme, err := de.themeManager.GetTheme(de.config.Theme)
+	if err != nil {
+		theme = DefaultTheme()
+	}
+	return theme.GetColor(name)
+}
+
+// renderCredit draws the credit line in its corner using the theme's credit color
+func (de *DisplayEngine) renderCredit() {
+	if de.credit == "" {
+		return
+	}
+	de.renderOverlayText(" "+de.credit+" ", de.creditCorner(), "\033[0;40m"+de.themeColor("credit"))
+}
+
+// creditCorner returns where the credit goes: the configured corner
+// (bottom-right by default), moved to the top row while a notice holds the
+// bottom one
+func (de *DisplayEngine) creditCorner() Corner {
+	corner := de.config.CreditCorner
+	if !corner.Valid() {
+		corner = CornerBottomRight
+	}
+	if de.notice != "" {
+		switch corner {
+		case CornerBottomLeft:
+			return CornerTopLeft
+		case CornerBottomRight:
+			return CornerTopRight
+		}
+	}
+	return corner
 } // loadAndProcess loads and processes the file content
 func (de *DisplayEngine) loadAndProcess(filePath string) ([]string, error) {
 	// Check cache first
@@ -404,22 +431,14 @@ func (de *DisplayEngine) loadAndProcess(filePath string) ([]string, error) {
 		lines = de.processUTF8(content)
 	case ModeCP437:
 		lines = de.processCP437(content)
-	case ModeCP437Raw:
+	case ModeCP437Raw, ModeAvatar:
 		lines = de.processCP437Raw(content)
+	case ModeASCII:
+		lines = de.processASCII(content)
 	default:
 		lines = de.processUTF8(content) // Default fallback
 	}
 
-	// Handle 80-column issue if enabled (for line-based ANSI)
-	if de.config.Columns.Handle80ColumnIssue {
-		lines = de.handle80ColumnIssue(lines)
-	}
-
-	// Handle 80-column issue for cursor-positioned ANSI (no line breaks)
-	if de.config.Columns.Handle80ColumnIssue && de.config.Width == 80 && len(lines) == 1 {
-		lines[0] = de.fix80ColumnCursorPositioning(lines[0])
-	}
-
 	// Cache the result
 	if de.config.Performance.CacheEnabled {
 		de.cache[filePath] = lines
@@ -428,211 +447,73 @@ func (de *DisplayEngine) loadAndProcess(filePath string) ([]string, error) {
 	return lines, nil
 }
 
+// artWidth is the width ANSI art is drawn for; wider terminals show it as-is
+// and narrower ones get it cropped
+const artWidth = 80
+
+// newScreen interprets file content onto a virtual screen. Art that SAUCE
+// says is wider than 80 columns is drawn at its own width (and cropped).
+func (de *DisplayEngine) newScreen(content []byte) *vscreen.Screen {
+	content, record := sauce.Split(content)
+	width := artWidth
+	if record != nil && record.Width() > artWidth {
+		width = record.Width()
+	}
+
+	screen := vscreen.New(width)
+	screen.UTF8 = de.config.Mode == ModeUTF8
+	screen.Write(content)
+	return screen
+}
+
+// lineWidth returns how many columns of row y to send. With the 80-column fix
+// a row reaching the terminal's last column loses that column, since printing
+// there makes many terminals wrap and scroll the screen.
+func (de *DisplayEngine) lineWidth(screen *vscreen.Screen, y int) int {
+	cols := de.config.Width
+	if cols <= 0 {
+		cols = artWidth
+	}
+	if de.config.Columns.Handle80ColumnIssue && screen.Len(y) >= cols {
+		cols--
+	}
+	return cols
+}
+
+// screenLines returns the screen's rows as ANSI lines fitted to the terminal
+func (de *DisplayEngine) screenLines(screen *vscreen.Screen) []string {
+	lines := make([]string, screen.Height())
+	for y := range lines {
+		lines[y] = screen.ANSI(y, de.lineWidth(screen, y))
+	}
+	return lines
+}
+
 // processUTF8 processes UTF-8 content
 func (de *DisplayEngine) processUTF8(content []byte) []string {
-	noSauce := trimStringFromSauce(string(content))
-	return strings.Split(noSauce, "\r\n")
+	return de.screenLines(de.newScreen(content))
 }
 
 // processCP437 processes CP437 content with UTF-8 conversion (for local mode)
 func (de *DisplayEngine) processCP437(content []byte) []string {
-	noSauce := trimStringFromSauce(string(content))
-	lines := strings.Split(noSauce, "\r\n")
+	lines := de.screenLines(de.newScreen(content))
 
 	// Convert each line from CP437 to UTF-8
-	converted := make([]string, len(lines))
 	for i, line := range lines {
 		// Convert line from CP437 to UTF-8 for local display
 		utf8Line, err := charmap.CodePage437.NewDecoder().String(line)
 		if err != nil {
 			utf8Line = line // Fallback to original
 		}
-
-		// Apply terminal-specific fixes if needed
-		utf8Line = de.applyTerminalFixes(utf8Line)
-		converted[i] = utf8Line
+		lines[i] = utf8Line
 	}
 
-	return converted
-}
-
-// applyTerminalFixes applies fixes for terminal compatibility issues
-func (de *DisplayEngine) applyTerminalFixes(line string) string {
-	// For now, return as-is. This can be extended to detect terminal
-	// capabilities and apply appropriate transformations
-	return line
+	return lines
 }
 
 // processCP437Raw processes CP437 content without conversion (for BBS mode)
 func (de *DisplayEngine) processCP437Raw(content []byte) []string {
-	noSauce := trimStringFromSauce(string(content))
-	return strings.Split(noSauce, "\r\n")
-}
-
-// handle80ColumnIssue handles the 80-column line break issue
-// When a line has 80 or more visible characters on an 80-column terminal,
-// the cursor auto-wraps to the next line, causing unwanted screen scrolling
-func (de *DisplayEngine) handle80ColumnIssue(lines []string) []string {
-	if de.config.Width != 80 {
-		return lines
-	}
-
-	result := make([]string, len(lines))
-	for i, line := range lines {
-		visibleCount := countVisibleChars(line)
-		if visibleCount >= 80 {
-			// Remove the last visible character to prevent auto-wrap
-			result[i] = removeLastVisibleChar(line)
-		} else {
-			result[i] = line
-		}
-	}
-	return result
-}
-
-// countVisibleChars counts visible characters in a string, excluding ANSI escape sequences
-func countVisibleChars(s string) int {
-	count := 0
-	i := 0
-
-	for i < len(s) {
-		if s[i] == '\x1b' {
-			// Start of ANSI escape sequence - skip the entire sequence
-			i++
-			if i < len(s) && s[i] == '[' {
-				// CSI sequence: ESC [ ... letter
-				i++
-				// Skip until we find the terminating letter (64-126 range for ANSI)
-				for i < len(s) {
-					ch := s[i]
-					i++
-					// ANSI CSI sequences end with a byte in the range 0x40-0x7E (64-126)
-					if ch >= 0x40 && ch <= 0x7E {
-						break
-					}
-				}
-			} else if i < len(s) {
-				// Other escape sequence (like ESC 7, ESC 8, etc.) - skip one more char
-				i++
-			}
-			continue
-		}
-
-		// Count visible character
-		count++
-		i++
-	}
-
-	return count
-}
-
-// removeLastVisibleChar removes the last visible character from a string while preserving ANSI codes
-func removeLastVisibleChar(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-
-	// Find all visible character positions
-	var visiblePositions []int
-	i := 0
-
-	for i < len(s) {
-		if s[i] == '\x1b' {
-			// Skip ANSI escape sequence
-			i++
-			if i < len(s) && s[i] == '[' {
-				// CSI sequence: ESC [ ... letter
-				i++
-				for i < len(s) {
-					ch := s[i]
-					i++
-					if ch >= 0x40 && ch <= 0x7E {
-						break
-					}
-				}
-			} else if i < len(s) {
-				// Other escape sequence - skip one more char
-				i++
-			}
-			continue
-		}
-
-		// This is a visible character
-		visiblePositions = append(visiblePositions, i)
-		i++
-	}
-
-	// If no visible characters, return original
-	if len(visiblePositions) == 0 {
-		return s
-	}
-
-	// Remove the last visible character
-	lastVisiblePos := visiblePositions[len(visiblePositions)-1]
-	return s[:lastVisiblePos] + s[lastVisiblePos+1:]
-}
-
-// fix80ColumnCursorPositioning fixes ANSI art that uses cursor positioning (ESC[row;colH)
-// by ensuring nothing is ever positioned at column 80 of the last row
-func (de *DisplayEngine) fix80ColumnCursorPositioning(content string) string {
-	if de.config.Width != 80 {
-		return content
-	}
-
-	result := strings.Builder{}
-	result.Grow(len(content))
-
-	i := 0
-	for i < len(content) {
-		if content[i] == '\x1b' && i+1 < len(content) && content[i+1] == '[' {
-			// Found ESC[, look for cursor positioning command (ends with H or f)
-			seqStart := i
-			i += 2
-
-			// Collect the parameters
-			paramStart := i
-			for i < len(content) && (content[i] >= '0' && content[i] <= '9' || content[i] == ';') {
-				i++
-			}
-
-			if i < len(content) && (content[i] == 'H' || content[i] == 'f') {
-				// This is a cursor positioning command
-				params := content[paramStart:i]
-				terminator := content[i]
-				i++
-
-				// Parse row;col
-				parts := strings.Split(params, ";")
-				if len(parts) == 2 {
-					row := 0
-					col := 0
-					fmt.Sscanf(parts[0], "%d", &row)
-					fmt.Sscanf(parts[1], "%d", &col)
-
-					// If positioning at column 80 on the last row, change to column 79
-					if row == de.config.Height && col == 80 {
-						result.WriteString(fmt.Sprintf("\x1b[%d;79%c", row, terminator))
-						continue
-					}
-				}
-
-				// Write original sequence
-				result.WriteString(content[seqStart:i])
-			} else {
-				// Not a cursor positioning command, write what we've read
-				result.WriteString(content[seqStart:i])
-				if i < len(content) {
-					result.WriteByte(content[i])
-					i++
-				}
-			}
-		} else {
-			result.WriteByte(content[i])
-			i++
-		}
-	}
-
-	return result.String()
+	return de.screenLines(de.newScreen(content))
 }
 
 // renderNormal renders content without scrolling
@@ -650,24 +531,9 @@ func (de *DisplayEngine) renderNormal(lines []string) error {
 	}
 
 	for i := 0; i < linesToDisplay; i++ {
-		line := lines[i]
 		// Last line is the last one we're displaying in the viewport
 		isLastLine := i == linesToDisplay-1
-
-		// CRITICAL 80-COLUMN FIX: When displaying exactly a full screen (25 lines on a 25-line terminal)
-		// AND this is the last line (line 25), we MUST prevent any content from reaching column 80
-		// because the terminal will auto-wrap after printing the 80th character, causing unwanted scroll
-		if isLastLine && linesToDisplay == de.config.Height && de.config.Width == 80 {
-			// Check if this line has 80+ visible characters
-			visibleCount := countVisibleChars(line)
-			// Keep removing characters until we're under 80 to handle lines with 80+ chars
-			for visibleCount >= 80 {
-				line = removeLastVisibleChar(line)
-				visibleCount = countVisibleChars(line)
-			}
-		}
-
-		de.printLine(line, isLastLine)
+		de.printLine(lines[i], isLastLine)
 	}
 
 	// Force output flush after rendering all lines
@@ -675,82 +541,37 @@ func (de *DisplayEngine) renderNormal(lines []string) error {
 	return nil
 }
 
-// renderWithScrolling renders content with scrolling support
-func (de *DisplayEngine) renderWithScrolling(lines []string) error {
-	de.scrollState.TotalLines = len(lines)
-	de.scrollState.CurrentLine = 0
-	de.updateScrollState()
-
-	return de.renderVisibleLines(lines)
-}
-
-// renderVisibleLines renders the currently visible lines
-func (de *DisplayEngine) renderVisibleLines(lines []string) error {
-	de.ClearScreen()
-
-	startLine := de.scrollState.CurrentLine
-	endLine := startLine + de.config.Height
-	if endLine > len(lines) {
-		endLine = len(lines)
-	}
-
-	// Calculate number of lines we'll actually render
-	numLines := endLine - startLine
-	lineIndex := 0
-
-	for i := startLine; i < endLine; i++ {
-		line := lines[i]
-		// Last line is the last one in this viewport render
-		isLastLine := lineIndex == numLines-1
-		de.printLine(line, isLastLine)
-		lineIndex++
-	}
-
-	// Don't show scroll indicators - removed to save screen space
-	de.flushOutput() // Ensure visible lines are sent to terminal
-
-	return nil
-}
-
-// ScrollUp scrolls up one line
+// ScrollUp scrolls the art on screen up one line
 func (de *DisplayEngine) ScrollUp() error {
-	if de.scrollState.CurrentLine > 0 {
-		de.scrollState.CurrentLine--
-		de.updateScrollState()
-		// Re-render with new scroll position
-		if de.currentContent != nil {
-			return de.renderVisibleLines(de.currentContent)
-		}
+	if de.viewer != nil {
+		de.viewer.Scroll(-1)
 	}
 	return nil
 }
 
-// ScrollDown scrolls down one line
+// ScrollDown scrolls the art on screen down one line
 func (de *DisplayEngine) ScrollDown() error {
-	if de.scrollState.CurrentLine < de.scrollState.TotalLines-de.config.Height {
-		de.scrollState.CurrentLine++
-		de.updateScrollState()
-		// Re-render with new scroll position
-		if de.currentContent != nil {
-			return de.renderVisibleLines(de.currentContent)
-		}
+	if de.viewer != nil {
+		de.viewer.Scroll(1)
 	}
 	return nil
 }
 
 // GetScrollState returns the current scroll state
 func (de *DisplayEngine) GetScrollState() ScrollState {
+	if de.viewer != nil {
+		return de.viewer.state()
+	}
 	return de.scrollState
 }
 
-// updateScrollState updates the scroll state flags
-func (de *DisplayEngine) updateScrollState() {
-	de.scrollState.CanScrollUp = de.scrollState.CurrentLine > 0
-	de.scrollState.CanScrollDown = de.scrollState.CurrentLine < de.scrollState.TotalLines-de.config.Height
-}
-
 // ClearScreen clears the screen
 func (de *DisplayEngine) ClearScreen() error {
+	if de.isASCII() {
+		de.output.Write([]byte("\f")) // Form feed clears most plain-text terminals
+		de.flushOutput()
+		return nil
+	}
 	de.output.Write([]byte(EraseScreen))
 	de.MoveCursor(0, 0)
 	de.flushOutput() // Ensure clear screen is sent immediately
@@ -759,6 +580,9 @@ func (de *DisplayEngine) ClearScreen() error {
 
 // MoveCursor moves the cursor to the specified position
 func (de *DisplayEngine) MoveCursor(x, y int) error {
+	if de.isASCII() {
+		return nil
+	}
 	de.output.Write([]byte(fmt.Sprintf(Esc+"%d;%df", y, x)))
 	return nil
 }
@@ -768,6 +592,14 @@ func (de *DisplayEngine) GetDimensions() (width, height int) {
 	return de.config.Width, de.config.Height
 }
 
+// SetSize changes the screen size for the following screens, forgetting art
+// laid out for the old one
+func (de *DisplayEngine) SetSize(width, height int) {
+	de.config.Width, de.config.Height = width, height
+	de.scrollState.VisibleLines = height
+	de.ClearCache()
+}
+
 // SetTheme sets the display theme (placeholder for future implementation)
 func (de *DisplayEngine) SetTheme(theme string) error {
 	de.config.Theme = theme
@@ -776,18 +608,33 @@ func (de *DisplayEngine) SetTheme(theme string) error {
 
 // HideCursor hides the terminal cursor
 func (de *DisplayEngine) HideCursor() {
+	if de.isASCII() {
+		return
+	}
 	de.output.Write([]byte(HideCursor))
 }
 
 // ShowCursor shows the terminal cursor
 func (de *DisplayEngine) ShowCursor() {
+	if de.isASCII() {
+		return
+	}
 	de.output.Write([]byte(ShowCursor))
 }
 
+// ResetColors restores the default text attributes
+func (de *DisplayEngine) ResetColors() {
+	if de.isASCII() {
+		return
+	}
+	de.output.Write([]byte(Reset))
+	de.flushOutput()
+}
+
 // EnableBlinkMode enables ANSI blink mode by disabling ICE mode
 // This allows blinking ANSI art to display properly
 func (de *DisplayEngine) EnableBlinkMode() {
-	if !de.config.NoIce {
+	if !de.config.NoIce && !de.isASCII() {
 		de.output.Write([]byte(DisableIceMode))
 		de.flushOutput()
 	}
@@ -796,7 +643,7 @@ func (de *DisplayEngine) EnableBlinkMode() {
 // DisableBlinkMode restores ICE mode (disables blink, enables high backgrounds)
 // This should be called on program exit to restore terminal defaults
 func (de *DisplayEngine) DisableBlinkMode() {
-	if !de.config.NoIce {
+	if !de.config.NoIce && !de.isASCII() {
 		de.output.Write([]byte(EnableIceMode))
 		de.flushOutput()
 	}
@@ -818,18 +665,7 @@ const (
 
 // printLine handles newline behavior per mode
 func (de *DisplayEngine) printLine(line string, isLastLine bool) {
-	// CRITICAL 80x25 FIX: For single-line ANSI art that fills exactly 25 rows (2000 visible chars)
-	// we must prevent the 2000th character from being printed to avoid auto-wrap scroll
-	if isLastLine && de.config.Width == 80 && de.config.Height == 25 {
-		visibleCount := countVisibleChars(line)
-		// If this single line has 2000+ chars, keep removing until under 2000
-		for visibleCount >= 2000 {
-			line = removeLastVisibleChar(line)
-			visibleCount = countVisibleChars(line)
-		}
-	}
-
-	if de.config.Mode == ModeCP437Raw {
+	if de.config.Mode == ModeCP437Raw || de.config.Mode == ModeAvatar {
 		de.output.Write([]byte(line))
 		// Only add line break if not the last line to avoid trailing breaks
 		if !isLastLine {
@@ -844,25 +680,3 @@ func (de *DisplayEngine) printLine(line string, isLastLine bool) {
 		de.output.Write([]byte(line + "\r\n"))
 	}
 }
-
-// trimStringFromSauce trims SAUCE metadata from a string
-func trimStringFromSauce(s string) string {
-	if idx := strings.Index(s, "COMNT"); idx != -1 {
-		leftOfDelimiter := strings.Split(s, "COMNT")[0]
-		return trimLastChar(leftOfDelimiter)
-	}
-	if idx := strings.Index(s, "SAUCE00"); idx != -1 {
-		leftOfDelimiter := strings.Split(s, "SAUCE00")[0]
-		return trimLastChar(leftOfDelimiter)
-	}
-	return s
-}
-
-// trimLastChar trims the last character from a string
-func trimLastChar(s string) string {
-	if len(s) > 0 {
-		_, size := utf8.DecodeLastRuneInString(s)
-		return s[:len(s)-size]
-	}
-	return s
-}
@@ -0,0 +1,157 @@
+package display
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/robbiew/advent/internal/vscreen"
+)
+
+func TestCreditOverlay(t *testing.T) {
+	artFS := fstest.MapFS{"art/DAY.ANS": {Data: []byte("\x1b[31mart\r\n")}}
+	cfg := DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "winter"}
+
+	tests := []struct {
+		corner Corner
+		move   string
+	}{
+		{CornerTopLeft, "\x1b[1;1H"},
+		{CornerTopRight, "\x1b[1;70H"},
+		{CornerBottomLeft, "\x1b[25;1H"},
+		{CornerBottomRight, "\x1b[25;70H"},
+		{"", "\x1b[25;70H"}, // Unset falls back to bottom-right
+	}
+	for _, tt := range tests {
+		cfg.CreditCorner = tt.corner
+		de := NewDisplayEngine(cfg, artFS)
+		var out bytes.Buffer
+		de.SetBBSConnection(&out)
+		de.SetCredit("Day 1: X")
+
+		if err := de.Display("art/DAY.ANS", User{}); err != nil {
+			t.Fatal(err)
+		}
+		want := tt.move + "\x1b[0;40m\x1b[1;36m Day 1: X " + Reset
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("corner %q: output %q does not contain %q", tt.corner, out.String(), want)
+		}
+	}
+}
+
+func TestCreditMakesWayForNotice(t *testing.T) {
+	artFS := fstest.MapFS{"art/DAY.ANS": {Data: []byte("art\r\n")}}
+	cfg := DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "winter", CreditCorner: CornerBottomLeft}
+	de := NewDisplayEngine(cfg, artFS)
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	// Both on at once: the notice keeps the bottom row, the credit goes above
+	de.SetCredit("Day 1: X")
+	de.SetNotice("Welcome back!")
+	if err := de.Display("art/DAY.ANS", User{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\x1b[25;1H\x1b[0;40m\x1b[1;37m Welcome back! " + Reset,
+		"\x1b[1;1H\x1b[0;40m\x1b[1;36m Day 1: X " + Reset,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+
+	// A notice arriving later blanks the credit and draws it above
+	de.SetNotice("")
+	de.Display("art/DAY.ANS", User{})
+	out.Reset()
+	de.UpdateNotice("That screen needs security level 20")
+	for _, want := range []string{
+		"\x1b[25;1H" + Reset + strings.Repeat(" ", 10) + Reset,
+		"\x1b[25;1H\x1b[0;40m\x1b[1;37m That screen needs security level 20 " + Reset,
+		"\x1b[1;1H\x1b[0;40m\x1b[1;36m Day 1: X " + Reset,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("UpdateNotice() output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestNoticeOverlay(t *testing.T) {
+	artFS := fstest.MapFS{"art/WELCOME.ANS": {Data: []byte("welcome\r\n")}}
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "classic"}, artFS)
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	de.SetNotice("New since your last visit: day 11, 12")
+	if err := de.Display("art/WELCOME.ANS", User{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\x1b[25;1H\x1b[0;40m\x1b[1;33m New since your last visit: day 11, 12 " + Reset
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("output %q does not contain %q", out.String(), want)
+	}
+
+	out.Reset()
+	de.SetNotice("")
+	de.Display("art/WELCOME.ANS", User{})
+	if strings.Contains(out.String(), "last visit") {
+		t.Error("cleared notice was drawn again")
+	}
+}
+
+func TestUpdateNotice(t *testing.T) {
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "classic"}, fstest.MapFS{})
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	de.SetNotice("Advent opens in 1 minute, 5 seconds")
+	de.UpdateNotice("Advent opens in 59 seconds")
+	// Redrawn in place, padded over the end of the longer notice
+	want := "\x1b7\x1b[25;1H\x1b[0;40m\x1b[1;33m Advent opens in 59 seconds          " + Reset + "\x1b8"
+	if out.String() != want {
+		t.Errorf("UpdateNotice() wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestDisplayText(t *testing.T) {
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeASCII, Width: 20, Height: 25}, fstest.MapFS{})
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	de.DisplayText([]string{"SYSOP MENU", "[R] Reload art from disk"})
+	// Plain text for ASCII callers, cut to fit
+	if want := "\fSYSOP MENU\r\n[R] Reload art from\r\n"; out.String() != want {
+		t.Errorf("DisplayText() wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestToast(t *testing.T) {
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "classic"}, fstest.MapFS{})
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	de.Toast("Day 12 is now open!")
+	// Drawn over the current screen: no clear, cursor saved and restored
+	want := "\x1b7\x1b[1;59H\x1b[0;40m\x1b[1;33m Day 12 is now open! " + Reset + "\x1b8"
+	if out.String() != want {
+		t.Errorf("Toast() wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestHostileSauceWidth(t *testing.T) {
+	// A few KB of newlines under a SAUCE record claiming 65535 columns
+	record := make([]byte, 128)
+	copy(record, "SAUCE00")
+	record[94] = 1 // Character data
+	binary.LittleEndian.PutUint16(record[96:], 65535)
+	content := append([]byte(strings.Repeat("\n", 3000)+"\x1a"), record...)
+
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25}, fstest.MapFS{})
+	screen := de.newScreen(content)
+	if screen.Width != vscreen.MaxWidth {
+		t.Errorf("screen width = %d, want it capped at %d", screen.Width, vscreen.MaxWidth)
+	}
+}
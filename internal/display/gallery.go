@@ -0,0 +1,147 @@
+package display
+
+import (
+	"fmt"
+	"io/fs"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// GalleryItem is one entry in a gallery's list
+type GalleryItem struct {
+	Label  string // Such as "2024"
+	Detail string // Such as "all 25 days"
+}
+
+// Gallery is a generated screen listing items beside a shrunken preview of
+// the selected one's art
+type Gallery struct {
+	Title    string
+	Items    []GalleryItem
+	Selected int    // Index into Items
+	Preview  string // Art path previewed beside the list ("" for none)
+}
+
+// galleryListWidth is the columns the list takes, cursor marker included
+const galleryListWidth = 30
+
+// galleryTop is the row the list and preview start on, below the title
+const galleryTop = 3
+
+// DisplayGallery clears the screen and draws a gallery. The list scrolls to
+// keep the selected item in view; ASCII callers get the list alone.
+func (de *DisplayEngine) DisplayGallery(g Gallery) {
+	de.ResetColors()
+	de.ClearScreen()
+	de.viewer = nil
+
+	if de.isASCII() {
+		de.writeASCIIGallery(g)
+	} else {
+		de.writeGallery(g)
+	}
+	de.renderOverlays()
+	de.flushOutput()
+}
+
+// writeGallery draws the title, list and preview with cursor positioning
+func (de *DisplayEngine) writeGallery(g Gallery) {
+	de.output.Write([]byte("\033[1;1H" + de.themeColor("accent") + " " + g.Title + Reset))
+
+	// The bottom row is left for the notice
+	rows := de.config.Height - galleryTop
+	first := listStart(g.Selected, len(g.Items), rows)
+	for i := first; i < len(g.Items) && i-first < rows; i++ {
+		line := galleryLine(g.Items[i], i == g.Selected)
+		color := de.themeColor("primary")
+		if i == g.Selected {
+			color = de.themeColor("accent") + "\033[7m"
+		}
+		de.output.Write([]byte(fmt.Sprintf("\033[%d;1H", galleryTop+i-first) + color + line + Reset))
+	}
+
+	left := galleryListWidth + 2
+	width := de.usableWidth() - left + 1
+	if g.Preview == "" || width < 10 {
+		return
+	}
+	lines, err := de.previewLines(g.Preview, width, rows)
+	if err != nil {
+		return
+	}
+	for y, line := range lines {
+		de.output.Write([]byte(fmt.Sprintf("\033[%d;%dH", galleryTop+y, left) + line))
+	}
+}
+
+// writeASCIIGallery lists the items as plain text, marking the selected one
+func (de *DisplayEngine) writeASCIIGallery(g Gallery) {
+	de.output.Write([]byte(g.Title + "\r\n\r\n"))
+	for i, item := range g.Items {
+		de.output.Write([]byte(galleryLine(item, i == g.Selected) + "\r\n"))
+	}
+}
+
+// galleryLine returns an item as a list line exactly galleryListWidth wide
+func galleryLine(item GalleryItem, selected bool) string {
+	marker := " "
+	if selected {
+		marker = ">"
+	}
+	line := fmt.Sprintf("%s %-6s %s", marker, item.Label, item.Detail)
+	if len(line) > galleryListWidth {
+		return line[:galleryListWidth]
+	}
+	return fmt.Sprintf("%-*s", galleryListWidth, line)
+}
+
+// listStart returns the first of total items to show in rows so the
+// selected one is in view, centered once the list scrolls
+func listStart(selected, total, rows int) int {
+	if total <= rows || rows < 1 {
+		return 0
+	}
+	first := selected - rows/2
+	if first > total-rows {
+		first = total - rows
+	}
+	if first < 0 {
+		first = 0
+	}
+	return first
+}
+
+// previewLines returns art shrunk to fit within width x height, keeping its
+// proportions, as lines for the caller's terminal
+func (de *DisplayEngine) previewLines(filePath string, width, height int) ([]string, error) {
+	content, err := fs.ReadFile(de.fs, filePath)
+	if err != nil {
+		return nil, err
+	}
+	screen := de.newScreen(content)
+	artHeight := screen.Height()
+	if artHeight == 0 {
+		return nil, nil
+	}
+	if h := artHeight * width / screen.Width; h <= height {
+		height = h
+	} else {
+		width = screen.Width * height / artHeight
+	}
+	if width < 1 || height < 1 {
+		return nil, nil
+	}
+
+	thumb := screen.Scale(width, height)
+	lines := make([]string, height)
+	for y := range lines {
+		lines[y] = thumb.ANSI(y, width)
+		if de.config.Mode == ModeCP437 {
+			// Local mode shows CP437 art converted to UTF-8
+			if utf8Line, err := charmap.CodePage437.NewDecoder().String(lines[y]); err == nil {
+				lines[y] = utf8Line
+			}
+		}
+	}
+	return lines, nil
+}
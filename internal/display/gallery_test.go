@@ -0,0 +1,62 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestListStart(t *testing.T) {
+	tests := []struct {
+		selected, total, rows, want int
+	}{
+		{0, 3, 10, 0},    // Everything fits
+		{2, 30, 10, 0},   // Near the top
+		{15, 30, 10, 10}, // Centered
+		{29, 30, 10, 20}, // Near the bottom
+	}
+	for _, tt := range tests {
+		if got := listStart(tt.selected, tt.total, tt.rows); got != tt.want {
+			t.Errorf("listStart(%d, %d, %d) = %d, want %d", tt.selected, tt.total, tt.rows, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayGallery(t *testing.T) {
+	artFS := fstest.MapFS{"art/2024/WELCOME.ANS": {Data: []byte(strings.Repeat("\x1b[32m"+strings.Repeat("#", 80), 24))}}
+	g := Gallery{
+		Title:    "Choose a year",
+		Items:    []GalleryItem{{"2023", "all 25 days"}, {"2024", "all 25 days - locked"}},
+		Selected: 1,
+		Preview:  "art/2024/WELCOME.ANS",
+	}
+
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "classic"}, artFS)
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+	de.DisplayGallery(g)
+	for _, want := range []string{
+		"\x1b[3;1H\x1b[37m  2023   all 25 days          " + Reset,
+		"\x1b[4;1H\x1b[33m\x1b[7m> 2024   all 25 days - locked " + Reset,
+		// 80x24 art shrunk to 48x14 beside the list
+		"\x1b[3;32H\x1b[32m" + strings.Repeat("#", 48) + "\x1b[0m",
+		"\x1b[16;32H",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("gallery %q does not contain %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "\x1b[17;32H") {
+		t.Error("preview is taller than 14 rows")
+	}
+
+	de = NewDisplayEngine(DisplayConfig{Mode: ModeASCII, Width: 80, Height: 25}, artFS)
+	out.Reset()
+	de.SetBBSConnection(&out)
+	de.DisplayGallery(g)
+	want := "\fChoose a year\r\n\r\n  2023   all 25 days          \r\n> 2024   all 25 days - locked \r\n"
+	if out.String() != want {
+		t.Errorf("ASCII gallery = %q, want %q", out.String(), want)
+	}
+}
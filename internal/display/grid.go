@@ -0,0 +1,189 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DoorState is how a day's door looks on the calendar grid
+type DoorState int
+
+const (
+	DoorLocked DoorState = iota // Not open yet
+	DoorNew                     // Unlocked since the caller's last visit, not opened yet
+	DoorOpen                    // Open a while, but the caller hasn't opened it
+	DoorOpened                  // Already opened by the caller
+)
+
+// gridColumns is the number of doors across (and down) the calendar grid
+const gridColumns = 5
+
+// Grid is a generated calendar of a year's doors
+type Grid struct {
+	Title    string
+	Doors    [gridColumns * gridColumns]DoorState // Indexed by day - 1
+	Selected int                                  // Day under the cursor
+}
+
+// doorStyle is how doors in one state are drawn
+type doorStyle struct {
+	state DoorState
+	color string // Theme color
+	name  string
+	mark  string // Told apart by this for ASCII callers
+}
+
+// doorLegend lists the door styles in the order the legend shows them
+var doorLegend = []doorStyle{
+	{DoorNew, "door_new", "new", "*"},
+	{DoorOpen, "door_open", "open", "+"},
+	{DoorOpened, "door_opened", "opened", " "},
+	{DoorLocked, "door_locked", "locked", "-"},
+}
+
+// legendEntry returns how doors in a state are drawn
+func legendEntry(state DoorState) doorStyle {
+	for _, entry := range doorLegend {
+		if entry.state == state {
+			return entry
+		}
+	}
+	return doorLegend[len(doorLegend)-1]
+}
+
+// gridLayout is where the grid's doors go on screen (1-based)
+type gridLayout struct {
+	left, top             int
+	doorWidth, doorHeight int
+	gapX, gapY            int // Space between doors
+}
+
+// layoutGrid sizes the doors to fill width x height, leaving the top rows
+// for the title and the bottom row for the notice
+func layoutGrid(width, height int) gridLayout {
+	l := gridLayout{top: 3} // Below the title and a blank line
+	rows := height - l.top
+	if rows < gridColumns*2-1 {
+		l.top = 2
+		rows = height - l.top
+	}
+	l.doorHeight, l.gapY = fitDoors(rows, 1)
+	l.doorWidth, l.gapX = fitDoors(width, 4)
+
+	used := gridColumns*l.doorWidth + (gridColumns-1)*l.gapX
+	l.left = 1 + (width-used)/2
+	if l.left < 1 {
+		l.left = 1
+	}
+	return l
+}
+
+// fitDoors splits space into gridColumns doors with a gap between each,
+// dropping the gaps when that leaves doors smaller than minSize
+func fitDoors(space, minSize int) (size, gap int) {
+	if size = (space+1)/gridColumns - 1; size >= minSize {
+		return size, 1
+	}
+	if size = space / gridColumns; size >= 1 {
+		return size, 0
+	}
+	return 1, 0
+}
+
+// DisplayGrid clears the screen and draws a calendar grid sized to the
+// terminal, with the notice along the bottom
+func (de *DisplayEngine) DisplayGrid(g Grid) {
+	de.ResetColors()
+	de.ClearScreen()
+	de.viewer = nil
+
+	if de.isASCII() {
+		de.writeASCIIGrid(g)
+	} else {
+		de.writeGrid(g)
+	}
+	de.renderOverlays()
+	de.flushOutput()
+}
+
+// writeGrid draws the title, legend and doors with cursor positioning
+func (de *DisplayEngine) writeGrid(g Grid) {
+	title := " " + g.Title
+	de.output.Write([]byte("\033[1;1H" + de.themeColor("accent") + title + Reset))
+
+	// The legend goes top-right when there's room beside the title
+	var legend strings.Builder
+	legendWidth := 0
+	for _, entry := range doorLegend {
+		legend.WriteString(de.themeColor(entry.color) + " " + entry.name + " " + Reset + " ")
+		legendWidth += len(entry.name) + 3
+	}
+	if col := de.config.Width - legendWidth; col > len(title)+1 {
+		de.output.Write([]byte(fmt.Sprintf("\033[1;%dH", col) + legend.String()))
+	}
+
+	l := layoutGrid(de.usableWidth(), de.config.Height)
+	for i, state := range g.Doors {
+		day := i + 1
+		x := l.left + i%gridColumns*(l.doorWidth+l.gapX)
+		y := l.top + i/gridColumns*(l.doorHeight+l.gapY)
+
+		color := de.themeColor(legendEntry(state).color)
+		if day == g.Selected {
+			color += "\033[7m" // Reverse video marks the cursor
+		}
+
+		label := strconv.Itoa(day)
+		if day == g.Selected && len(label)+2 <= l.doorWidth {
+			label = ">" + label + "<"
+		}
+		middle := (l.doorHeight - 1) / 2
+		for row := 0; row < l.doorHeight; row++ {
+			if y+row >= de.config.Height {
+				break // Leave the bottom row to the notice
+			}
+			text := ""
+			switch {
+			case row == middle:
+				text = label
+			case row == middle+1 && state == DoorNew:
+				text = "new"
+			}
+			de.output.Write([]byte(fmt.Sprintf("\033[%d;%dH", y+row, x) + color +
+				center(text, l.doorWidth) + Reset))
+		}
+	}
+}
+
+// writeASCIIGrid lists the doors as plain text, five to a line
+func (de *DisplayEngine) writeASCIIGrid(g Grid) {
+	lines := []string{g.Title, ""}
+	for row := 0; row < gridColumns; row++ {
+		line := " "
+		for col := 0; col < gridColumns; col++ {
+			day := row*gridColumns + col + 1
+			open, close := " ", " "
+			if day == g.Selected {
+				open, close = "[", "]"
+			}
+			mark := legendEntry(g.Doors[day-1]).mark
+			line += fmt.Sprintf(" %s%2d%s%s", open, day, mark, close)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "  * new  - locked  [ ] cursor")
+
+	for _, line := range lines {
+		de.output.Write([]byte(line + "\r\n"))
+	}
+}
+
+// center pads text with spaces to width, keeping it in the middle
+func center(text string, width int) string {
+	if len(text) > width {
+		text = text[:width]
+	}
+	left := (width - len(text)) / 2
+	return strings.Repeat(" ", left) + text + strings.Repeat(" ", width-len(text)-left)
+}
@@ -0,0 +1,61 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLayoutGrid(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          gridLayout
+	}{
+		{79, 25, gridLayout{left: 1, top: 3, doorWidth: 15, doorHeight: 3, gapX: 1, gapY: 1}},
+		{131, 50, gridLayout{left: 2, top: 3, doorWidth: 25, doorHeight: 8, gapX: 1, gapY: 1}},
+		{39, 12, gridLayout{left: 1, top: 3, doorWidth: 7, doorHeight: 1, gapX: 1, gapY: 1}},
+		{19, 8, gridLayout{left: 3, top: 2, doorWidth: 3, doorHeight: 1, gapX: 0, gapY: 0}},
+	}
+	for _, tt := range tests {
+		if got := layoutGrid(tt.width, tt.height); got != tt.want {
+			t.Errorf("layoutGrid(%d, %d) = %+v, want %+v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayGrid(t *testing.T) {
+	g := Grid{Title: "Advent 2025", Selected: 3}
+	for day := 1; day <= 4; day++ {
+		g.Doors[day-1] = DoorOpened
+	}
+	g.Doors[4] = DoorNew
+	g.Doors[5] = DoorOpen
+
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeASCII, Width: 80, Height: 25}, fstest.MapFS{})
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+	de.DisplayGrid(g)
+	for _, want := range []string{
+		"\fAdvent 2025\r\n\r\n",
+		"    1     2   [ 3 ]   4     5* \r\n",
+		"    6+    7-    8-    9-   10- \r\n",
+		"   21-   22-   23-   24-   25- \r\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("ASCII grid %q does not contain %q", out.String(), want)
+		}
+	}
+
+	de = NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Theme: "classic"}, fstest.MapFS{})
+	out.Reset()
+	de.SetBBSConnection(&out)
+	de.DisplayGrid(g)
+	// Day 3 is under the cursor, second row of its door; day 5 is new
+	if want := "\x1b[4;33H\x1b[0;37;44m\x1b[7m      >3<      " + Reset; !strings.Contains(out.String(), want) {
+		t.Errorf("ANSI grid %q does not contain %q", out.String(), want)
+	}
+	if want := "\x1b[5;65H\x1b[0;1;33;41m      new      " + Reset; !strings.Contains(out.String(), want) {
+		t.Errorf("ANSI grid %q does not contain %q", out.String(), want)
+	}
+}
@@ -19,13 +19,19 @@ func DefaultTheme() *Theme {
 		Name:        "classic",
 		Description: "Classic ANSI art theme",
 		Colors: map[string]string{
-			"primary":   "\033[37m", // White
-			"secondary": "\033[36m", // Cyan
-			"accent":    "\033[33m", // Yellow
-			"error":     "\033[31m", // Red
-			"success":   "\033[32m", // Green
-			"warning":   "\033[33m", // Yellow
-			"info":      "\033[34m", // Blue
+			"primary":     "\033[37m",        // White
+			"secondary":   "\033[36m",        // Cyan
+			"accent":      "\033[33m",        // Yellow
+			"error":       "\033[31m",        // Red
+			"success":     "\033[32m",        // Green
+			"warning":     "\033[33m",        // Yellow
+			"info":        "\033[34m",        // Blue
+			"credit":      "\033[1;37m",      // Bright white
+			"notice":      "\033[1;33m",      // Bright yellow
+			"door_new":    "\033[0;1;33;41m", // Bright yellow on red
+			"door_open":   "\033[0;30;46m",   // Black on cyan
+			"door_opened": "\033[0;37;44m",   // White on blue
+			"door_locked": "\033[0;1;30;47m", // Grey on white
 		},
 		Styles: map[string]string{
 			"title":     "bold",
@@ -42,13 +48,19 @@ func ChristmasTheme() *Theme {
 		Name:        "christmas",
 		Description: "Festive Christmas theme",
 		Colors: map[string]string{
-			"primary":   "\033[31m", // Red
-			"secondary": "\033[32m", // Green
-			"accent":    "\033[33m", // Gold/Yellow
-			"error":     "\033[35m", // Magenta
-			"success":   "\033[32m", // Green
-			"warning":   "\033[33m", // Yellow
-			"info":      "\033[36m", // Cyan
+			"primary":     "\033[31m",        // Red
+			"secondary":   "\033[32m",        // Green
+			"accent":      "\033[33m",        // Gold/Yellow
+			"error":       "\033[35m",        // Magenta
+			"success":     "\033[32m",        // Green
+			"warning":     "\033[33m",        // Yellow
+			"info":        "\033[36m",        // Cyan
+			"credit":      "\033[1;33m",      // Bright yellow
+			"notice":      "\033[1;32m",      // Bright green
+			"door_new":    "\033[0;1;33;41m", // Bright yellow on red
+			"door_open":   "\033[0;30;43m",   // Black on yellow
+			"door_opened": "\033[0;1;37;42m", // Bright white on green
+			"door_locked": "\033[0;1;30;47m", // Grey on white
 		},
 		Styles: map[string]string{
 			"title":     "bold",
@@ -65,13 +77,19 @@ func WinterTheme() *Theme {
 		Name:        "winter",
 		Description: "Cool winter theme",
 		Colors: map[string]string{
-			"primary":   "\033[37m", // White
-			"secondary": "\033[34m", // Blue
-			"accent":    "\033[36m", // Cyan
-			"error":     "\033[31m", // Red
-			"success":   "\033[32m", // Green
-			"warning":   "\033[33m", // Yellow
-			"info":      "\033[35m", // Magenta
+			"primary":     "\033[37m",        // White
+			"secondary":   "\033[34m",        // Blue
+			"accent":      "\033[36m",        // Cyan
+			"error":       "\033[31m",        // Red
+			"success":     "\033[32m",        // Green
+			"warning":     "\033[33m",        // Yellow
+			"info":        "\033[35m",        // Magenta
+			"credit":      "\033[1;36m",      // Bright cyan
+			"notice":      "\033[1;37m",      // Bright white
+			"door_new":    "\033[0;1;37;46m", // Bright white on cyan
+			"door_open":   "\033[0;1;37;45m", // Bright white on magenta
+			"door_opened": "\033[0;1;37;44m", // Bright white on blue
+			"door_locked": "\033[0;1;30;47m", // Grey on white
 		},
 		Styles: map[string]string{
 			"title":     "bold",
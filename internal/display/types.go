@@ -11,8 +11,29 @@ const (
 	ModeCP437 DisplayMode = iota
 	ModeUTF8
 	ModeCP437Raw
+	ModeASCII  // Plain text for callers without ANSI (door32 emulation 0)
+	ModeAvatar // Raw CP437 translated to AVATAR/0+ codes (door32 emulation 2)
 )
 
+// Corner is a screen corner where overlay text can be drawn
+type Corner string
+
+const (
+	CornerTopLeft     Corner = "top-left"
+	CornerTopRight    Corner = "top-right"
+	CornerBottomLeft  Corner = "bottom-left"
+	CornerBottomRight Corner = "bottom-right"
+)
+
+// Valid reports whether c names one of the four corners
+func (c Corner) Valid() bool {
+	switch c {
+	case CornerTopLeft, CornerTopRight, CornerBottomLeft, CornerBottomRight:
+		return true
+	}
+	return false
+}
+
 // ScrollState represents the current scrolling state
 type ScrollState struct {
 	CurrentLine   int
@@ -24,31 +45,32 @@ type ScrollState struct {
 
 // DisplayConfig holds display-related configuration
 type DisplayConfig struct {
-	Mode        DisplayMode
-	Width       int
-	Height      int
-	Theme       string
-	Scrolling   ScrollingConfig
-	Columns     ColumnConfig
-	Performance PerformanceConfig
-	NoIce       bool // Disable ICE mode control codes
+	Mode         DisplayMode
+	Width        int
+	Height       int
+	Theme        string
+	Scrolling    ScrollingConfig
+	Columns      ColumnConfig
+	Performance  PerformanceConfig
+	NoIce        bool   // Disable ICE mode control codes
+	CreditCorner Corner // Where the art credit line is drawn
 }
 
 type ScrollingConfig struct {
-	Enabled           bool
-	Indicators        bool
-	KeyboardShortcuts bool
+	Enabled           bool `json:"enabled"`
+	Indicators        bool `json:"indicators"`
+	KeyboardShortcuts bool `json:"keyboard_shortcuts"`
 }
 
 type ColumnConfig struct {
-	Handle80ColumnIssue bool
-	AutoDetectWidth     bool
+	Handle80ColumnIssue bool `json:"handle_80_column_issue"`
+	AutoDetectWidth     bool `json:"auto_detect_width"`
 }
 
 type PerformanceConfig struct {
-	CacheEnabled bool
-	CacheSizeMB  int
-	PreloadLines int
+	CacheEnabled bool `json:"cache_enabled"`
+	CacheSizeMB  int  `json:"cache_size_mb"`
+	PreloadLines int  `json:"preload_lines"`
 }
 
 // Displayer interface for display operations
@@ -65,12 +87,15 @@ type Displayer interface {
 
 // User represents BBS user information
 type User struct {
-	Alias     string
-	TimeLeft  time.Duration
-	Emulation int
-	NodeNum   int
-	H         int
-	W         int
-	ModalH    int
-	ModalW    int
+	Alias         string
+	BBSName       string // BBS the caller is on, from the dropfile
+	UserRecord    int    // Caller's record number on that BBS, 0 when unknown
+	SecurityLevel int
+	TimeLeft      time.Duration
+	Emulation     int
+	NodeNum       int
+	H             int
+	W             int
+	ModalH        int
+	ModalW        int
 }
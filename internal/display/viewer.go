@@ -0,0 +1,273 @@
+package display
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sgrPattern matches the color codes in processed lines, for searching
+var sgrPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Viewer scrolls through art taller than the screen: a line or a page at a
+// time, to either end, or between lines matching a search. The bottom row
+// shows which lines are on screen. Moves redraw only the art rows.
+type Viewer struct {
+	de      *DisplayEngine
+	lines   []string // Processed for the caller's terminal
+	text    []string // Lower-case plain text of each line, for searching
+	footer  []string // Menu bar drawn below the art, if any
+	rows    int      // Lines of art on screen
+	top     int      // First line on screen
+	status  Corner   // Where the position goes
+	drawn   int      // Width of the position last drawn, to blank it out
+	query   string
+	matches []int // Lines matching query
+	match   int   // Index into matches of the one shown
+	message string
+}
+
+// maxMenuBarRows caps how much of the screen FOOTER.ANS can take
+const maxMenuBarRows = 2
+
+// NewViewer returns a viewer of lines from LoadAnsiLines. With menuBar set
+// the FOOTER.ANS menu bar is drawn below them, as on the info and members
+// screens; otherwise only the bottom row is kept for the position.
+func (de *DisplayEngine) NewViewer(lines []string, menuBar bool) *Viewer {
+	v := &Viewer{de: de, lines: lines, status: CornerBottomRight, match: -1}
+	if menuBar {
+		v.footer = de.menuBar()
+		v.status = CornerBottomLeft
+	}
+	reserved := len(v.footer)
+	if reserved < 1 {
+		reserved = 1
+	}
+	v.rows = de.config.Height - reserved
+	if v.rows < 1 {
+		v.rows = 1
+	}
+
+	v.text = make([]string, len(lines))
+	for i, line := range lines {
+		v.text[i] = strings.ToLower(sgrPattern.ReplaceAllString(line, ""))
+	}
+	return v
+}
+
+// Viewer returns the viewer for the art on screen when it's taller than the
+// screen, or nil when it fits
+func (de *DisplayEngine) Viewer() *Viewer {
+	return de.viewer
+}
+
+// Draw clears the screen and draws the viewer's current page, menu bar,
+// overlays and position
+func (v *Viewer) Draw() {
+	de := v.de
+	de.ResetColors()
+	de.ClearScreen()
+	de.viewer = v
+	v.drawn = 0
+
+	if de.isASCII() {
+		// ASCII callers can't scroll a window; page through the rest instead
+		de.renderPaged(v.lines[v.top:])
+		for _, line := range v.footer {
+			de.output.Write([]byte("\r\n" + line))
+		}
+		de.renderOverlays()
+		de.output.Write([]byte("\r\n" + v.Position()))
+		de.flushOutput()
+		return
+	}
+
+	v.drawLines()
+	if len(v.footer) > 0 {
+		de.output.Write([]byte(fmt.Sprintf("\033[%d;1H", de.config.Height-len(v.footer)+1)))
+		de.output.Write([]byte(strings.Join(v.footer, "\r\n")))
+	}
+	de.renderOverlays()
+	v.drawPosition()
+	de.flushOutput()
+}
+
+// Scroll moves the view by n lines (up when negative). ASCII callers have
+// no window to scroll, so it only moves for them by whole pages.
+func (v *Viewer) Scroll(n int) {
+	if v.de.isASCII() {
+		return
+	}
+	v.moveTo(v.top + n)
+}
+
+// Page moves the view by n screens (up when negative)
+func (v *Viewer) Page(n int) {
+	v.moveTo(v.top + n*v.rows)
+}
+
+// Home moves to the first line
+func (v *Viewer) Home() {
+	v.moveTo(0)
+}
+
+// End moves to the last page
+func (v *Viewer) End() {
+	v.moveTo(len(v.lines))
+}
+
+// Search moves to the first line containing query (in any case) from the
+// top of the screen on, wrapping around. The screen is redrawn in full to
+// clear the prompt the query was typed at.
+func (v *Viewer) Search(query string) {
+	v.query = strings.TrimSpace(query)
+	v.matches = nil
+	v.match = -1
+	v.message = ""
+	if v.query != "" {
+		lower := strings.ToLower(v.query)
+		for i, text := range v.text {
+			if strings.Contains(text, lower) {
+				v.matches = append(v.matches, i)
+			}
+		}
+		if len(v.matches) == 0 {
+			v.message = fmt.Sprintf("%q not found", v.query)
+		} else {
+			v.match = 0
+			for i, line := range v.matches {
+				if line >= v.top {
+					v.match = i
+					break
+				}
+			}
+			v.top = v.clamp(v.matches[v.match])
+		}
+	}
+	v.Draw()
+}
+
+// Searching reports whether the last search found anything, so Next and
+// Prev have somewhere to go
+func (v *Viewer) Searching() bool {
+	return len(v.matches) > 0
+}
+
+// Next moves to the next line matching the search, wrapping around
+func (v *Viewer) Next() {
+	if len(v.matches) > 0 {
+		v.match = (v.match + 1) % len(v.matches)
+		v.moveTo(v.matches[v.match])
+	}
+}
+
+// Prev moves to the previous line matching the search, wrapping around
+func (v *Viewer) Prev() {
+	if len(v.matches) > 0 {
+		v.match = (v.match + len(v.matches) - 1) % len(v.matches)
+		v.moveTo(v.matches[v.match])
+	}
+}
+
+// Position describes what's on screen, such as "Lines 24-46 of 120 (38%)",
+// after the search result if there is one
+func (v *Viewer) Position() string {
+	bottom := v.top + v.rows
+	if bottom > len(v.lines) {
+		bottom = len(v.lines)
+	}
+	percent := 100
+	if len(v.lines) > 0 {
+		percent = bottom * 100 / len(v.lines)
+	}
+	position := fmt.Sprintf("Lines %d-%d of %d (%d%%)", v.top+1, bottom, len(v.lines), percent)
+
+	switch {
+	case v.message != "":
+		return v.message + " - " + position
+	case v.match >= 0:
+		return fmt.Sprintf("%q %d of %d, n/N for next/previous - %s", v.query, v.match+1, len(v.matches), position)
+	}
+	return position
+}
+
+// state returns the viewer's position as a ScrollState
+func (v *Viewer) state() ScrollState {
+	return ScrollState{
+		CurrentLine:   v.top,
+		TotalLines:    len(v.lines),
+		VisibleLines:  v.rows,
+		CanScrollUp:   v.top > 0,
+		CanScrollDown: v.top < v.clamp(len(v.lines)),
+	}
+}
+
+// clamp keeps a first line between the top and the last page
+func (v *Viewer) clamp(top int) int {
+	if last := len(v.lines) - v.rows; top > last {
+		top = last
+	}
+	if top < 0 {
+		top = 0
+	}
+	return top
+}
+
+// moveTo shows the page starting at top, redrawing only what changed
+func (v *Viewer) moveTo(top int) {
+	top = v.clamp(top)
+	moved := top != v.top
+	v.top = top
+	v.message = ""
+
+	if v.de.isASCII() {
+		if moved {
+			v.Draw()
+		}
+		return
+	}
+	if moved {
+		v.drawLines()
+	}
+	v.drawPosition()
+	v.de.flushOutput()
+}
+
+// drawLines draws the art rows in place, clearing what each one doesn't cover
+func (v *Viewer) drawLines() {
+	for row := 0; row < v.rows; row++ {
+		v.de.output.Write([]byte(fmt.Sprintf("\033[%d;1H", row+1)))
+		if i := v.top + row; i < len(v.lines) {
+			v.de.output.Write([]byte(v.lines[i]))
+		}
+		v.de.output.Write([]byte("\033[K"))
+	}
+}
+
+// drawPosition draws the position in its corner of the bottom row, blanking
+// out the end of a longer one drawn before
+func (v *Viewer) drawPosition() {
+	text := " " + v.Position() + " "
+	if pad := v.drawn - len(text); pad > 0 {
+		if v.status == CornerBottomRight {
+			text = strings.Repeat(" ", pad) + text
+		} else {
+			text += strings.Repeat(" ", pad)
+		}
+	}
+	v.drawn = len(text)
+	v.de.renderOverlayText(text, v.status, "\033[0;40m"+v.de.themeColor("notice"))
+}
+
+// menuBar returns the lines of FOOTER.ANS, the menu bar under scrolling
+// screens, up to maxMenuBarRows
+func (de *DisplayEngine) menuBar() []string {
+	lines, err := de.loadAndProcess("art/common/FOOTER.ANS")
+	if err != nil {
+		return nil
+	}
+	if len(lines) > maxMenuBarRows {
+		lines = lines[:maxMenuBarRows]
+	}
+	return lines
+}
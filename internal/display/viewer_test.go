@@ -0,0 +1,96 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestViewer(t *testing.T) {
+	de := NewDisplayEngine(DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 10, Theme: "classic"}, fstest.MapFS{})
+	var out bytes.Buffer
+	de.SetBBSConnection(&out)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("\x1b[32mline %d\x1b[0m", i))
+	}
+	v := de.NewViewer(lines, false)
+	v.Draw()
+
+	moves := []struct {
+		name     string
+		move     func()
+		position string
+	}{
+		{"page down", func() { v.Page(1) }, "Lines 10-18 of 30 (60%)"},
+		{"end", v.End, "Lines 22-30 of 30 (100%)"},
+		{"down at the end", func() { v.Scroll(1) }, "Lines 22-30 of 30 (100%)"},
+		{"home", v.Home, "Lines 1-9 of 30 (30%)"},
+		{"down", func() { v.Scroll(1) }, "Lines 2-10 of 30 (33%)"},
+	}
+	for _, m := range moves {
+		m.move()
+		if got := v.Position(); got != m.position {
+			t.Errorf("%s: Position() = %q, want %q", m.name, got, m.position)
+		}
+	}
+
+	// Scrolling redraws the art rows and position in place
+	out.Reset()
+	v.Scroll(1)
+	if strings.Contains(out.String(), EraseScreen) {
+		t.Error("scrolling cleared the screen")
+	}
+	for _, want := range []string{"\x1b[1;1H\x1b[32mline 3\x1b[0m\x1b[K", " Lines 3-11 of 30 (36%) "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("scrolled output %q does not contain %q", out.String(), want)
+		}
+	}
+
+	// "line 2" is in lines 2 and 20-29, matched in any case
+	v.Search("LINE 2")
+	if got := v.Position(); got != `"LINE 2" 2 of 11, n/N for next/previous - Lines 20-28 of 30 (93%)` {
+		t.Errorf("Search() from line 3: Position() = %q", got)
+	}
+	v.Prev()
+	if got := v.state().CurrentLine; got != 1 {
+		t.Errorf("Prev() showed line %d first, want 1", got)
+	}
+	v.Prev() // Wraps around to line 29, on the last page
+	if got := v.state().CurrentLine; got != 21 {
+		t.Errorf("Prev() from the first match showed line %d first, want 21", got)
+	}
+
+	v.Search("snow")
+	if v.Searching() || !strings.HasPrefix(v.Position(), `"snow" not found`) {
+		t.Errorf("Search(snow) = %q, searching %v", v.Position(), v.Searching())
+	}
+}
+
+func TestTallArtScrolls(t *testing.T) {
+	art := strings.Repeat("row\r\n", 40)
+	artFS := fstest.MapFS{"art/DAY.ANS": {Data: []byte(art)}}
+	cfg := DisplayConfig{Mode: ModeCP437Raw, Width: 80, Height: 25, Scrolling: ScrollingConfig{Enabled: true}}
+	de := NewDisplayEngine(cfg, artFS)
+	de.SetBBSConnection(&bytes.Buffer{})
+
+	if err := de.Display("art/DAY.ANS", User{}); err != nil {
+		t.Fatal(err)
+	}
+	if de.Viewer() == nil {
+		t.Fatal("art taller than the screen has no viewer")
+	}
+	de.ScrollDown()
+	if got := de.GetScrollState(); got.CurrentLine != 1 || got.VisibleLines != 24 || !got.CanScrollUp {
+		t.Errorf("GetScrollState() after ScrollDown() = %+v", got)
+	}
+
+	artFS["art/SHORT.ANS"] = &fstest.MapFile{Data: []byte("row\r\n")}
+	de.Display("art/SHORT.ANS", User{})
+	if de.Viewer() != nil {
+		t.Error("art that fits still has a viewer")
+	}
+}
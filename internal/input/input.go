@@ -1,9 +1,12 @@
 package input
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/robbiew/advent/internal/bbs"
 	"github.com/sirupsen/logrus"
@@ -44,17 +47,43 @@ const (
 	KeyF12
 )
 
+// ErrTimeout is returned by ReadKeyTimeout when no key arrives in time
+var ErrTimeout = errors.New("input: timed out waiting for a key")
+
+// ErrWoken is returned by ReadKeyTimeout when Wake is called while it waits
+var ErrWoken = errors.New("input: woken while waiting for a key")
+
+// keyPress is the result of one read
+type keyPress struct {
+	char rune
+	key  Key
+	err  error
+}
+
 // InputHandler manages keyboard input
 type InputHandler struct {
 	oldState  *term.State
 	bbsConn   *bbs.BBSConnection
+	reader    io.Reader // Network session input (built-in server mode)
 	isWindows bool
+	pending   chan keyPress // Read still in progress after a ReadKeyTimeout gave up
+	wake      chan struct{} // Signaled by Wake
 }
 
 // NewInputHandler creates a new input handler
 func NewInputHandler() *InputHandler {
 	return &InputHandler{
 		isWindows: runtime.GOOS == "windows",
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Wake has the current or next ReadKeyTimeout return ErrWoken, such as when
+// the caller's terminal has been resized. It's safe to call from any goroutine.
+func (ih *InputHandler) Wake() {
+	select {
+	case ih.wake <- struct{}{}:
+	default: // Already pending
 	}
 }
 
@@ -63,8 +92,19 @@ func (ih *InputHandler) SetBBSConnection(conn *bbs.BBSConnection) {
 	ih.bbsConn = conn
 }
 
+// SetReader makes the handler read keys from r instead of the console,
+// for callers connected to the built-in server
+func (ih *InputHandler) SetReader(r io.Reader) {
+	ih.reader = r
+}
+
 // Open initializes the terminal for raw input
 func (ih *InputHandler) Open() error {
+	if ih.reader != nil {
+		// Network session - the client's terminal is already in character mode
+		return nil
+	}
+
 	if ih.isWindows && ih.bbsConn != nil {
 		// Windows with socket connection - no terminal setup needed
 		return nil
@@ -103,11 +143,50 @@ func (ih *InputHandler) Close() error {
 
 // ReadKey reads a single key press
 func (ih *InputHandler) ReadKey() (rune, Key, error) {
+	if ih.pending != nil {
+		// A timed-out read is still waiting - its key is the next one
+		press := <-ih.pending
+		ih.pending = nil
+		return press.char, press.key, press.err
+	}
+	return ih.readKey()
+}
+
+// ReadKeyTimeout reads a single key press, giving up with ErrTimeout after
+// timeout. The read carries on in the background, so a key arriving later
+// is returned by the next ReadKey or ReadKeyTimeout rather than lost.
+func (ih *InputHandler) ReadKeyTimeout(timeout time.Duration) (rune, Key, error) {
+	if ih.pending == nil {
+		ih.pending = make(chan keyPress, 1)
+		go func(pending chan<- keyPress) {
+			char, key, err := ih.readKey()
+			pending <- keyPress{char, key, err}
+		}(ih.pending)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case press := <-ih.pending:
+		ih.pending = nil
+		return press.char, press.key, press.err
+	case <-timer.C:
+		return 0, KeyUnknown, ErrTimeout
+	case <-ih.wake:
+		return 0, KeyUnknown, ErrWoken
+	}
+}
+
+// readKey reads and decodes one key press from the input
+func (ih *InputHandler) readKey() (rune, Key, error) {
 	var buf [256]byte
 	var n int
 	var err error
 
-	if ih.isWindows && ih.bbsConn != nil {
+	if ih.reader != nil {
+		// Built-in server: read from the caller's connection
+		n, err = ih.reader.Read(buf[:])
+	} else if ih.isWindows && ih.bbsConn != nil {
 		// Windows: Read from BBS socket connection
 		n, err = ih.bbsConn.Read(buf[:])
 	} else {
@@ -213,6 +292,44 @@ func (ih *InputHandler) ReadKey() (rune, Key, error) {
 	return 0, KeyUnknown, nil
 }
 
+// keySequences are the byte sequences ReadKey recognizes for each special key
+var keySequences = map[Key]string{
+	KeyEsc:        "\x1b",
+	KeyEnter:      "\r",
+	KeySpace:      " ",
+	KeyBackspace:  "\x7f",
+	KeyTab:        "\t",
+	KeyArrowUp:    "\x1b[A",
+	KeyArrowDown:  "\x1b[B",
+	KeyArrowRight: "\x1b[C",
+	KeyArrowLeft:  "\x1b[D",
+	KeyPageUp:     "\x1b[5~",
+	KeyPageDown:   "\x1b[6~",
+	KeyHome:       "\x1b[H",
+	KeyEnd:        "\x1b[F",
+	KeyInsert:     "\x1b[2~",
+	KeyDelete:     "\x1b[3~",
+	KeyF1:         "\x1bOP",
+	KeyF2:         "\x1bOQ",
+	KeyF3:         "\x1bOR",
+	KeyF4:         "\x1bOS",
+	KeyF5:         "\x1b[15~",
+	KeyF6:         "\x1b[17~",
+	KeyF7:         "\x1b[18~",
+	KeyF8:         "\x1b[19~",
+	KeyF9:         "\x1b[20~",
+	KeyF10:        "\x1b[21~",
+	KeyF11:        "\x1b[23~",
+	KeyF12:        "\x1b[24~",
+}
+
+// KeySequence returns the terminal byte sequence for a special key, as
+// ReadKey expects to receive it. Used by front ends that get key events
+// rather than a byte stream (e.g. the web terminal). Returns "" for KeyUnknown.
+func KeySequence(key Key) string {
+	return keySequences[key]
+}
+
 // IsPrintable checks if a rune is printable
 func IsPrintable(r rune) bool {
 	return r >= 32 && r <= 126
@@ -0,0 +1,70 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeySequenceRoundTrip(t *testing.T) {
+	for key := range keySequences {
+		ih := NewInputHandler()
+		ih.SetReader(strings.NewReader(KeySequence(key)))
+
+		char, got, err := ih.ReadKey()
+		if err != nil {
+			t.Fatalf("%s: ReadKey() error: %v", KeyToString(key), err)
+		}
+		if got != key {
+			t.Errorf("%s: ReadKey() = %s (char %q)", KeyToString(key), KeyToString(got), char)
+		}
+	}
+
+	if KeySequence(KeyUnknown) != "" {
+		t.Error("KeyUnknown should have no sequence")
+	}
+}
+
+func TestReadKeyTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	ih := NewInputHandler()
+	ih.SetReader(r)
+
+	if _, _, err := ih.ReadKeyTimeout(10 * time.Millisecond); err != ErrTimeout {
+		t.Fatalf("ReadKeyTimeout() with no input = %v, want ErrTimeout", err)
+	}
+
+	// The key typed after the timeout goes to the next read
+	go w.Write([]byte("x"))
+	char, _, err := ih.ReadKey()
+	if err != nil || char != 'x' {
+		t.Fatalf("ReadKey() = %q, %v, want 'x'", char, err)
+	}
+
+	go w.Write([]byte(KeySequence(KeyArrowLeft)))
+	if _, key, err := ih.ReadKeyTimeout(time.Second); err != nil || key != KeyArrowLeft {
+		t.Errorf("ReadKeyTimeout() = %s, %v, want left arrow", KeyToString(key), err)
+	}
+}
+
+func TestWake(t *testing.T) {
+	r, w := io.Pipe()
+	ih := NewInputHandler()
+	ih.SetReader(r)
+
+	ih.Wake()
+	ih.Wake() // Wakes don't pile up
+	if _, _, err := ih.ReadKeyTimeout(time.Second); err != ErrWoken {
+		t.Fatalf("ReadKeyTimeout() after Wake() = %v, want ErrWoken", err)
+	}
+	if _, _, err := ih.ReadKeyTimeout(10 * time.Millisecond); err != ErrTimeout {
+		t.Fatalf("second ReadKeyTimeout() = %v, want ErrTimeout", err)
+	}
+
+	// The read carries on, so a key typed after waking isn't lost
+	go w.Write([]byte("x"))
+	if char, _, err := ih.ReadKey(); err != nil || char != 'x' {
+		t.Errorf("ReadKey() = %q, %v, want 'x'", char, err)
+	}
+}
@@ -3,11 +3,13 @@ package navigation
 import (
 	"fmt"
 	"io/fs"
-	"path" // Use path instead of filepath for embedded FS (always forward slashes)
 	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/clock"
 )
 
 // ScreenType represents different screens in the application
@@ -18,11 +20,36 @@ const (
 	ScreenDay
 	ScreenComeback
 	ScreenYearSelect
-	ScreenInfo    // Info screen
-	ScreenMembers // Members screen
-	ScreenExit
+	ScreenInfo     // Info screen
+	ScreenMembers  // Members screen
+	ScreenCalendar // Generated grid of the year's days
+	ScreenNotYet   // Countdown to the coming season
+	ScreenCustom   // One of the sysop's own screens, named by State.Custom
+	ScreenExit     // Goodbye screen, shown on the way out
 )
 
+// screenNames are the names screens are saved under
+var screenNames = map[ScreenType]string{
+	ScreenWelcome:    "welcome",
+	ScreenDay:        "day",
+	ScreenComeback:   "comeback",
+	ScreenYearSelect: "yearselect",
+	ScreenInfo:       "info",
+	ScreenMembers:    "members",
+	ScreenCalendar:   "calendar",
+	ScreenNotYet:     "notyet",
+	ScreenCustom:     "custom",
+	ScreenExit:       "exit",
+}
+
+// String returns the screen's name, such as "day"
+func (s ScreenType) String() string {
+	if name, ok := screenNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("screen(%d)", int(s))
+}
+
 // Direction represents navigation direction
 type Direction int
 
@@ -45,24 +72,58 @@ type State struct {
 	Screen         ScreenType
 	MaxDay         int
 	AvailableYears []int
+	Cursor         int    // Day under the calendar grid's cursor, or year on the year select screen
+	Custom         string // Name of the sysop's screen on ScreenCustom
 }
 
+// calendarColumns is the number of days in each row of the calendar grid
+const calendarColumns = 5
+
 // Navigator handles navigation logic
 type Navigator struct {
-	baseArtDir      string
-	fs              fs.FS
+	baseArtDir       string
+	fs               fs.FS
+	resolver         *calendar.Resolver
+	clock            clock.Clock
 	disableDateCheck bool
 }
 
 // NewNavigator creates a new navigator
 func NewNavigator(embeddedFS fs.FS, baseArtDir string) *Navigator {
 	return &Navigator{
-		baseArtDir:      baseArtDir,
-		fs:              embeddedFS,
+		baseArtDir:       baseArtDir,
+		fs:               embeddedFS,
+		resolver:         calendar.NewResolver(embeddedFS, baseArtDir),
+		clock:            clock.System,
 		disableDateCheck: false,
 	}
 }
 
+// SetResolver shares a calendar resolver with other components
+func (n *Navigator) SetResolver(resolver *calendar.Resolver) {
+	n.resolver = resolver
+}
+
+// SetClock sets the clock days are unlocked by (for -debug-date and tests)
+func (n *Navigator) SetClock(c clock.Clock) {
+	n.clock = c
+}
+
+// Now returns the current time on the navigator's clock
+func (n *Navigator) Now() time.Time {
+	return n.clock.Now()
+}
+
+// Reload rereads the years' manifests, for art changed on disk
+func (n *Navigator) Reload() {
+	n.resolver.Reload()
+}
+
+// DateCheckDisabled reports whether every day is open regardless of the date
+func (n *Navigator) DateCheckDisabled() bool {
+	return n.disableDateCheck
+}
+
 // SetDisableDateCheck sets whether date checking should be disabled
 func (n *Navigator) SetDisableDateCheck(disable bool) {
 	n.disableDateCheck = disable
@@ -80,7 +141,7 @@ func (n *Navigator) GetAvailableYears() ([]int, error) {
 	for _, entry := range entries {
 		if entry.IsDir() {
 			year, err := strconv.Atoi(entry.Name())
-			if err == nil && year >= 2023 && year <= 2030 { // Reasonable year range
+			if err == nil && calendar.ValidYear(year) {
 				// Include all year directories - missing art will show MISSING.ANS
 				years = append(years, year)
 				logrus.WithField("year", year).Debug("Found year directory")
@@ -114,10 +175,12 @@ func (n *Navigator) Navigate(direction Direction, currentState State) (newState
 		return n.navigateFromComeback(direction, currentState)
 	case ScreenYearSelect:
 		return n.navigateFromYearSelect(direction, currentState)
-	case ScreenInfo, ScreenMembers:
-		// Info and Members screens only support scrolling (handled elsewhere)
+	case ScreenCalendar:
+		return n.navigateFromCalendar(direction, currentState)
+	case ScreenInfo, ScreenMembers, ScreenCustom:
+		// Info, Members and the sysop's screens only support scrolling (handled elsewhere)
 		// Arrow keys, Page Up/Down, Home/End should be ignored for navigation
-		// Q/ESC handling is done in main loop
+		// Q/ESC is handled by Back
 		return currentState, "", nil
 	default:
 		return currentState, "", fmt.Errorf("unknown screen type: %d", currentState.Screen)
@@ -128,6 +191,11 @@ func (n *Navigator) Navigate(direction Direction, currentState State) (newState
 func (n *Navigator) navigateFromWelcome(direction Direction, state State) (State, string, error) {
 	switch direction {
 	case DirRight:
+		// A year with no open days shows its teaser and goes no further
+		if state.MaxDay < 1 {
+			return state, "", nil
+		}
+
 		// Move to current day screen
 		state.Screen = ScreenDay
 
@@ -207,13 +275,158 @@ func (n *Navigator) navigateFromComeback(direction Direction, state State) (Stat
 	}
 }
 
-// navigateFromYearSelect handles navigation from year selection screen
-func (n *Navigator) navigateFromYearSelect(_ Direction, state State) (State, string, error) {
-	// Year selection navigation would be implemented here
-	// For now, return to welcome
+// navigateFromYearSelect moves the cursor through the available years. The
+// screen is drawn rather than loaded, so there's never an art path.
+func (n *Navigator) navigateFromYearSelect(direction Direction, state State) (State, string, error) {
+	years := state.AvailableYears
+	if len(years) == 0 {
+		return state, "", nil
+	}
+	i := len(years) - 1 // The newest year when the cursor isn't on one
+	for j, year := range years {
+		if year == state.Cursor {
+			i = j
+		}
+	}
+
+	switch direction {
+	case DirUp, DirLeft:
+		if i > 0 {
+			i--
+		}
+	case DirDown, DirRight:
+		if i < len(years)-1 {
+			i++
+		}
+	case DirHome:
+		i = 0
+	case DirEnd:
+		i = len(years) - 1
+	}
+	state.Cursor = years[i]
+	return state, "", nil
+}
+
+// OpenYearSelect switches to the year select screen with the cursor on the
+// current year
+func (n *Navigator) OpenYearSelect(state State) State {
+	state.Screen = ScreenYearSelect
+	state.Cursor = state.CurrentYear
+	return state
+}
+
+// navigateFromCalendar moves the cursor around the calendar grid. The grid
+// is drawn rather than loaded, so there's never an art path.
+func (n *Navigator) navigateFromCalendar(direction Direction, state State) (State, string, error) {
+	switch direction {
+	case DirLeft:
+		if state.Cursor > 1 {
+			state.Cursor--
+		}
+	case DirRight:
+		if state.Cursor < calendar.LastDay {
+			state.Cursor++
+		}
+	case DirUp:
+		if state.Cursor > calendarColumns {
+			state.Cursor -= calendarColumns
+		}
+	case DirDown:
+		if state.Cursor+calendarColumns <= calendar.LastDay {
+			state.Cursor += calendarColumns
+		}
+	case DirHome:
+		state.Cursor = 1
+	case DirEnd:
+		// The last open day, or the last day of all when none are open
+		state.Cursor = state.MaxDay
+		if state.Cursor < 1 {
+			state.Cursor = calendar.LastDay
+		}
+	}
+	return state, "", nil
+}
+
+// OpenCalendar switches to the calendar grid with the cursor on the current day
+func (n *Navigator) OpenCalendar(state State) State {
+	state.Screen = ScreenCalendar
+	state.Cursor = state.CurrentDay
+	if state.Cursor < 1 || state.Cursor > calendar.LastDay {
+		state.Cursor = 1
+	}
+	return state
+}
+
+// OpenDay goes straight to a day of the current year, such as one picked on
+// the calendar grid. Days that aren't open yet return an error saying when
+// they will be.
+func (n *Navigator) OpenDay(state State, day int) (State, string, error) {
+	if day < 1 || day > calendar.LastDay {
+		return state, "", fmt.Errorf("there is no day %d", day)
+	}
+	if day > state.MaxDay {
+		// Days open in order, so this one waits for the latest before it
+		opens := n.resolver.UnlockTime(state.CurrentYear, day)
+		for d := state.MaxDay + 1; d < day; d++ {
+			if t := n.resolver.UnlockTime(state.CurrentYear, d); t.After(opens) {
+				opens = t
+			}
+		}
+		return state, "", fmt.Errorf("day %d opens %s", day, opens.Format("Jan 2 at 15:04 MST"))
+	}
+	state.Screen = ScreenDay
+	state.CurrentDay = day
+	state.Cursor = day
+	return state, n.getDayArtPath(state.CurrentYear, day), nil
+}
+
+// OpenScreen switches to a screen of the current year that needs nothing
+// else set up, such as info or members
+func (n *Navigator) OpenScreen(state State, screen ScreenType) State {
+	state.Screen = screen
+	return state
+}
+
+// OpenCustom switches to one of the sysop's own screens
+func (n *Navigator) OpenCustom(state State, name string) State {
+	state.Screen = ScreenCustom
+	state.Custom = name
+	return state
+}
+
+// Back returns where Q takes the caller. Info, members, the sysop's screens
+// and the generated screens go back to the year's welcome screen; everything
+// else goes to the newest year's welcome screen, and leaves the door
+// (ScreenExit) from there. When open (if given) reports the newest year is
+// closed to the caller, they go to this year's welcome screen instead and
+// leave from there.
+func (n *Navigator) Back(state State, open func(year int) bool) State {
+	state.Custom = ""
+	switch state.Screen {
+	case ScreenInfo, ScreenMembers, ScreenCustom, ScreenCalendar, ScreenYearSelect:
+		state.Screen = ScreenWelcome
+		return state
+	}
+
+	leaving := state.Screen == ScreenWelcome || state.Screen == ScreenComeback
+	if len(state.AvailableYears) == 0 {
+		state.Screen = ScreenExit
+		return state
+	}
+	latestYear := state.AvailableYears[len(state.AvailableYears)-1]
+	if state.CurrentYear != latestYear && open != nil && !open(latestYear) {
+		latestYear = state.CurrentYear
+	}
+	if state.CurrentYear == latestYear && leaving {
+		state.Screen = ScreenExit
+		return state
+	}
+	if state.CurrentYear != latestYear {
+		state.CurrentYear = latestYear
+		state.MaxDay = n.MaxDay(latestYear)
+	}
 	state.Screen = ScreenWelcome
-	artPath := n.getWelcomeArtPath(state.CurrentYear)
-	return state, artPath, nil
+	return state
 }
 
 // SetYear changes the current year
@@ -242,11 +455,22 @@ func (n *Navigator) SelectYearByIndex(index int, currentState State) (State, str
 	}
 
 	// Get the year (years are sorted ascending: oldest first)
-	selectedYear := currentState.AvailableYears[index-1]
+	return n.SelectYear(currentState.AvailableYears[index-1], currentState)
+}
+
+// SelectYear switches to one of the available years, starting on its welcome screen
+func (n *Navigator) SelectYear(selectedYear int, currentState State) (State, string, error) {
+	available := false
+	for _, year := range currentState.AvailableYears {
+		available = available || year == selectedYear
+	}
+	if !available {
+		return currentState, "", fmt.Errorf("year %d not available", selectedYear)
+	}
 
 	// Update state with new year
 	currentState.CurrentYear = selectedYear
-	currentState.MaxDay = n.calculateMaxDay()
+	currentState.MaxDay = n.MaxDay(selectedYear)
 
 	// When switching years, start with the year's welcome screen
 	currentState.Screen = ScreenWelcome
@@ -278,7 +502,7 @@ func (n *Navigator) GetInitialState() (State, error) {
 	}).Info("Selected initial year")
 
 	// Calculate max day for the year
-	maxDay := n.calculateMaxDay()
+	maxDay := n.MaxDay(selectedYear)
 
 	// For advent calendar, we always start at day 1
 	// The maxDay calculation will handle whether future days are accessible
@@ -295,76 +519,47 @@ func (n *Navigator) GetInitialState() (State, error) {
 	return state, nil
 }
 
-// calculateMaxDay calculates the maximum available day for a year
-func (n *Navigator) calculateMaxDay() int {
+// MaxDay returns the last day of a year that is open now, following the
+// resolver's unlock rule
+func (n *Navigator) MaxDay(year int) int {
 	// If date checking is disabled (debug mode), allow all 25 days
 	if n.disableDateCheck {
-		return 25
+		return calendar.LastDay
 	}
+	return n.resolver.MaxDay(year, n.clock.Now())
+}
 
-	now := time.Now()
+// SeasonStart returns when the first day of the coming season opens
+func (n *Navigator) SeasonStart() time.Time {
+	return n.resolver.SeasonStart(n.clock.Now())
+}
 
-	// If it's December, limit to the current day (max 25)
-	if now.Month() == time.December {
-		day := now.Day()
-		if day > 25 {
-			return 25
-		}
-		return day
+// UnlockedBetween returns the days of a year that opened after from and by
+// to, such as the days that opened since a caller's last visit
+func (n *Navigator) UnlockedBetween(year int, from, to time.Time) []int {
+	if n.disableDateCheck || !from.Before(to) {
+		return nil
 	}
-
-	// If not December, no days are accessible (should show NOTYET.ANS instead)
-	return 0
+	var days []int
+	for day := n.resolver.MaxDay(year, from) + 1; day <= n.resolver.MaxDay(year, to); day++ {
+		days = append(days, day)
+	}
+	return days
 }
 
 // getDayArtPath returns the path to a day's art file
 func (n *Navigator) getDayArtPath(year, day int) string {
-	yearDir := path.Join(n.baseArtDir, strconv.Itoa(year))
-	yearSuffix := strconv.Itoa(year)[2:]
-
-	// Try both zero-padded (01_DEC25.ANS) and single-digit (1_DEC25.ANS) formats
-	var primaryFileName, fallbackFileName string
-
-	// For 2025 and later, try zero-padded format first
-	if year >= 2025 {
-		// Zero-padded format (e.g., 01_DEC25.ANS)
-		primaryFileName = fmt.Sprintf("%02d_DEC%s.ANS", day, yearSuffix)
-		// Single-digit format as fallback (e.g., 1_DEC25.ANS)
-		fallbackFileName = fmt.Sprintf("%d_DEC%s.ANS", day, yearSuffix)
-	} else {
-		// For 2024 and earlier, try single-digit format first
-		primaryFileName = fmt.Sprintf("%d_DEC%s.ANS", day, yearSuffix)
-		// Zero-padded format as fallback
-		fallbackFileName = fmt.Sprintf("%02d_DEC%s.ANS", day, yearSuffix)
-	}
-
-	// Try primary format first
-	primaryPath := path.Join(yearDir, primaryFileName)
-	if _, err := fs.Stat(n.fs, primaryPath); err == nil {
-		return primaryPath
-	}
-
-	// Try fallback format if primary format doesn't exist
-	fallbackPath := path.Join(yearDir, fallbackFileName)
-	if _, err := fs.Stat(n.fs, fallbackPath); err == nil {
-		return fallbackPath
-	}
-
-	// If neither exists, return the primary format path
-	// This will eventually fall back to MISSING.ANS in the display engine
-	return primaryPath
+	return n.resolver.DayPath(year, day)
 }
 
 // getWelcomeArtPath returns the path to the welcome art file
 func (n *Navigator) getWelcomeArtPath(year int) string {
-	yearDir := path.Join(n.baseArtDir, strconv.Itoa(year))
-	return path.Join(yearDir, "WELCOME.ANS")
+	return n.resolver.ScreenPath(year, calendar.ScreenWelcome)
 }
 
 // getComebackArtPath returns the path to the comeback art file
 func (n *Navigator) getComebackArtPath(year int) string {
-	yearDir := path.Join(n.baseArtDir, strconv.Itoa(year))
-	return path.Join(yearDir, "COMEBACK.ANS")
+	return n.resolver.ScreenPath(year, calendar.ScreenComeback)
 }
 
 // ValidateState validates that the current state is consistent
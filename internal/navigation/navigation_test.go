@@ -0,0 +1,206 @@
+package navigation
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/robbiew/advent/internal/clock"
+)
+
+func TestUnlockedBetween(t *testing.T) {
+	date := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+	}
+	n := NewNavigator(fstest.MapFS{}, "art")
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []int
+	}{
+		{"same day", date(2025, 12, 10, 9), date(2025, 12, 10, 22), nil},
+		{"two days later", date(2025, 12, 10, 9), date(2025, 12, 12, 1), []int{11, 12}},
+		{"from before the season", date(2025, 11, 20, 9), date(2025, 12, 2, 9), []int{1, 2}},
+		{"last visited a year ago", date(2024, 12, 24, 9), date(2025, 12, 3, 9), []int{1, 2, 3}},
+		{"after the last day", date(2025, 12, 24, 9), date(2025, 12, 30, 9), []int{25}},
+		{"off season", date(2025, 12, 28, 9), date(2026, 1, 5, 9), nil},
+	}
+	for _, tt := range tests {
+		if got := n.UnlockedBetween(2025, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: UnlockedBetween() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// With date checks off every day is always open, so nothing is new
+	n.SetDisableDateCheck(true)
+	if got := n.UnlockedBetween(2025, date(2025, 12, 1, 9), date(2025, 12, 5, 9)); got != nil {
+		t.Errorf("date checks disabled: UnlockedBetween() = %v, want nil", got)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	n := NewNavigator(fstest.MapFS{}, "art")
+	s := NewScheduler(n)
+	at := func(month time.Month, day, hour, min int) {
+		n.SetClock(clock.Fixed(time.Date(2025, month, day, hour, min, 0, 0, time.Local)))
+	}
+
+	// A session still open at midnight on the 11th wakes for day 12
+	state := State{CurrentYear: 2025, MaxDay: 11}
+	at(12, 11, 23, 50)
+	if wait, ok := s.Wait(state); !ok || wait != 10*time.Minute {
+		t.Errorf("Wait() on Dec 11 23:50 = %v, %v, want 10m", wait, ok)
+	}
+	at(12, 12, 0, 0)
+	if opened := s.Apply(&state); !reflect.DeepEqual(opened, []int{12}) || state.MaxDay != 12 {
+		t.Errorf("Apply() at midnight opened %v, MaxDay %d", opened, state.MaxDay)
+	}
+	at(12, 12, 0, 5)
+	if opened := s.Apply(&state); opened != nil {
+		t.Errorf("Apply() opened %v again", opened)
+	}
+
+	// Long waits are capped so clock changes are picked up
+	at(11, 2, 12, 0)
+	if wait, ok := s.Wait(state); !ok || wait != time.Hour {
+		t.Errorf("Wait() in November = %v, %v, want 1h", wait, ok)
+	}
+	at(12, 25, 9, 0)
+	if _, ok := s.Wait(state); ok {
+		t.Error("Wait() after the last day should report nothing to wait for")
+	}
+
+	// MaxDay is never lowered
+	state = State{CurrentYear: 2025, MaxDay: 20}
+	at(12, 5, 0, 0)
+	if opened := s.Apply(&state); opened != nil || state.MaxDay != 20 {
+		t.Errorf("Apply() with MaxDay ahead of the clock opened %v, MaxDay %d", opened, state.MaxDay)
+	}
+}
+
+func TestLockedYear(t *testing.T) {
+	n := NewNavigator(fstest.MapFS{}, "art")
+	n.SetClock(clock.Fixed(time.Date(2025, 11, 20, 12, 0, 0, 0, time.Local)))
+
+	// Off season, past years are fully open and the coming one is locked
+	if got := n.MaxDay(2024); got != 25 {
+		t.Errorf("MaxDay(2024) in November 2025 = %d, want 25", got)
+	}
+	if got := n.MaxDay(2025); got != 0 {
+		t.Errorf("MaxDay(2025) in November 2025 = %d, want 0", got)
+	}
+
+	state := State{CurrentYear: 2025, CurrentDay: 1, Screen: ScreenWelcome, MaxDay: 0}
+	if got, path, _ := n.Navigate(DirRight, state); got.Screen != ScreenWelcome || path != "" {
+		t.Errorf("Navigate(right) from a locked year went to %v %q", got.Screen, path)
+	}
+}
+
+func TestCalendarGrid(t *testing.T) {
+	n := NewNavigator(fstest.MapFS{}, "art")
+	n.SetClock(clock.Fixed(time.Date(2025, 12, 12, 9, 0, 0, 0, time.Local)))
+
+	state := n.OpenCalendar(State{CurrentYear: 2025, CurrentDay: 7, Screen: ScreenDay, MaxDay: 12})
+	if state.Screen != ScreenCalendar || state.Cursor != 7 {
+		t.Fatalf("OpenCalendar() = %v with cursor %d, want calendar on day 7", state.Screen, state.Cursor)
+	}
+
+	moves := []struct {
+		dir  Direction
+		want int
+	}{
+		{DirDown, 12}, {DirDown, 17}, {DirDown, 22}, {DirDown, 22}, // Stays on the bottom row
+		{DirRight, 23}, {DirUp, 18}, {DirHome, 1}, {DirLeft, 1}, {DirUp, 1},
+		{DirEnd, 12}, // The last open day
+	}
+	for _, m := range moves {
+		state, _, _ = n.Navigate(m.dir, state)
+		if state.Cursor != m.want {
+			t.Errorf("Navigate(%d) moved the cursor to %d, want %d", m.dir, state.Cursor, m.want)
+		}
+	}
+
+	if _, _, err := n.OpenDay(state, 18); err == nil || err.Error() != "day 18 opens Dec 18 at 00:00 "+time.Date(2025, 12, 18, 0, 0, 0, 0, time.Local).Format("MST") {
+		t.Errorf("OpenDay(18) on Dec 12 = %v, want when it opens", err)
+	}
+	if _, _, err := n.OpenDay(state, 26); err == nil {
+		t.Error("OpenDay(26) should fail")
+	}
+	state, path, err := n.OpenDay(state, 11)
+	if err != nil || state.Screen != ScreenDay || state.CurrentDay != 11 || path != "art/2025/11_DEC25.ANS" {
+		t.Errorf("OpenDay(11) = %v day %d %q, %v", state.Screen, state.CurrentDay, path, err)
+	}
+}
+
+func TestYearSelect(t *testing.T) {
+	n := NewNavigator(fstest.MapFS{}, "art")
+	n.SetClock(clock.Fixed(time.Date(2025, 12, 12, 9, 0, 0, 0, time.Local)))
+
+	years := []int{2020, 2021, 2022, 2023, 2024, 2025, 2026, 2027, 2028, 2029, 2030}
+	state := n.OpenYearSelect(State{CurrentYear: 2025, Screen: ScreenWelcome, MaxDay: 12, AvailableYears: years})
+	if state.Screen != ScreenYearSelect || state.Cursor != 2025 {
+		t.Fatalf("OpenYearSelect() = %v with cursor %d, want year select on 2025", state.Screen, state.Cursor)
+	}
+
+	moves := []struct {
+		dir  Direction
+		want int
+	}{
+		{DirDown, 2026}, {DirEnd, 2030}, {DirDown, 2030}, {DirHome, 2020}, {DirUp, 2020}, {DirRight, 2021},
+	}
+	for _, m := range moves {
+		state, _, _ = n.Navigate(m.dir, state)
+		if state.Cursor != m.want {
+			t.Errorf("Navigate(%d) moved the cursor to %d, want %d", m.dir, state.Cursor, m.want)
+		}
+	}
+
+	// Past nine years there's no key for each year, but any can be selected
+	state, _, err := n.SelectYear(2030, state)
+	if err != nil || state.CurrentYear != 2030 || state.Screen != ScreenWelcome || state.MaxDay != 0 {
+		t.Errorf("SelectYear(2030) = %d %v MaxDay %d, %v", state.CurrentYear, state.Screen, state.MaxDay, err)
+	}
+	if _, _, err := n.SelectYear(2019, state); err == nil {
+		t.Error("SelectYear(2019) should fail for a year without art")
+	}
+}
+
+func TestBack(t *testing.T) {
+	n := NewNavigator(fstest.MapFS{}, "art")
+	n.SetClock(clock.Fixed(time.Date(2025, 12, 12, 9, 0, 0, 0, time.Local)))
+	years := []int{2024, 2025}
+
+	tests := []struct {
+		name   string
+		state  State
+		screen ScreenType
+		year   int
+	}{
+		{"custom screen", n.OpenCustom(State{CurrentYear: 2024, AvailableYears: years}, "rules"), ScreenWelcome, 2024},
+		{"info", State{CurrentYear: 2024, Screen: ScreenInfo, AvailableYears: years}, ScreenWelcome, 2024},
+		{"past day", State{CurrentYear: 2024, Screen: ScreenDay, AvailableYears: years}, ScreenWelcome, 2025},
+		{"past welcome", State{CurrentYear: 2024, Screen: ScreenWelcome, AvailableYears: years}, ScreenWelcome, 2025},
+		{"newest day", State{CurrentYear: 2025, Screen: ScreenDay, AvailableYears: years}, ScreenWelcome, 2025},
+		{"newest comeback", State{CurrentYear: 2025, Screen: ScreenComeback, AvailableYears: years}, ScreenExit, 2025},
+	}
+	for _, tt := range tests {
+		got := n.Back(tt.state, nil)
+		if got.Screen != tt.screen || got.CurrentYear != tt.year || got.Custom != "" {
+			t.Errorf("Back() from %s = %v %d %q, want %v %d", tt.name, got.Screen, got.CurrentYear, got.Custom, tt.screen, tt.year)
+		}
+		if tt.year != tt.state.CurrentYear && got.MaxDay != 12 {
+			t.Errorf("Back() from %s left MaxDay %d, want 12", tt.name, got.MaxDay)
+		}
+	}
+
+	// A caller the newest year is closed to stays in the year they're in
+	only2024 := func(year int) bool { return year == 2024 }
+	if got := n.Back(State{CurrentYear: 2024, Screen: ScreenDay, AvailableYears: years}, only2024); got.Screen != ScreenWelcome || got.CurrentYear != 2024 {
+		t.Errorf("Back() from a 2024 day with 2025 closed = %v %d, want 2024 welcome", got.Screen, got.CurrentYear)
+	}
+	if got := n.Back(State{CurrentYear: 2024, Screen: ScreenWelcome, AvailableYears: years}, only2024); got.Screen != ScreenExit {
+		t.Errorf("Back() from 2024 welcome with 2025 closed = %v, want exit", got.Screen)
+	}
+}
@@ -0,0 +1,55 @@
+package navigation
+
+import "time"
+
+// maxUnlockWait caps how long a session sleeps before checking the clock
+// again, so a changed system clock is noticed within the hour
+const maxUnlockWait = time.Hour
+
+// Scheduler opens days for a session that is still running when the next
+// day unlocks. The caller waits up to Wait() for input, then calls Apply.
+type Scheduler struct {
+	navigator *Navigator
+}
+
+// NewScheduler creates a scheduler following the navigator's unlock rules
+func NewScheduler(n *Navigator) *Scheduler {
+	return &Scheduler{navigator: n}
+}
+
+// Wait returns how long until the next day of the state's year unlocks,
+// capped at an hour. It reports false when nothing more unlocks.
+func (s *Scheduler) Wait(state State) (time.Duration, bool) {
+	if s.navigator.disableDateCheck {
+		return 0, false
+	}
+	now := s.navigator.Now()
+	next, ok := s.navigator.resolver.NextUnlock(state.CurrentYear, now)
+	if !ok {
+		return 0, false
+	}
+	wait := next.Sub(now)
+	if wait > maxUnlockWait {
+		wait = maxUnlockWait
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// Apply raises state.MaxDay to the last day of its year open now and returns
+// the days that opened. MaxDay never goes down.
+func (s *Scheduler) Apply(state *State) []int {
+	if s.navigator.disableDateCheck {
+		return nil
+	}
+	var opened []int
+	for day := state.MaxDay + 1; day <= s.navigator.MaxDay(state.CurrentYear); day++ {
+		opened = append(opened, day)
+	}
+	if len(opened) > 0 {
+		state.MaxDay = opened[len(opened)-1]
+	}
+	return opened
+}
@@ -0,0 +1,235 @@
+// Package sauce reads SAUCE (Standard Architecture for Universal Comment
+// Extensions) records, the 128-byte metadata trailer found at the end of most
+// ANSI art files, along with the optional COMNT comment block before it.
+package sauce
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+const (
+	// RecordSize is the size of the SAUCE record at the end of a file
+	RecordSize = 128
+	// CommentLineSize is the size of each line in the COMNT block
+	CommentLineSize = 64
+
+	recordID  = "SAUCE"
+	commentID = "COMNT"
+	eof       = 0x1A
+)
+
+// DataType is the kind of file a record describes
+type DataType uint8
+
+const (
+	DataNone DataType = iota
+	DataCharacter
+	DataBitmap
+	DataVector
+	DataAudio
+	DataBinaryText
+	DataXBin
+	DataArchive
+	DataExecutable
+)
+
+// File types of DataCharacter
+const (
+	FileASCII uint8 = iota
+	FileANSi
+	FileANSiMation
+	FileRIPScript
+	FilePCBoard
+	FileAvatar
+	FileHTML
+	FileSource
+	FileTundraDraw
+)
+
+// Record holds the fields of a SAUCE record. Text fields have their space
+// and NUL padding removed.
+type Record struct {
+	Version  string
+	Title    string
+	Author   string
+	Group    string
+	Date     string // CCYYMMDD as stored; see Time
+	FileSize uint32 // Size of the content before the EOF marker
+	DataType DataType
+	FileType uint8
+	TInfo1   uint16
+	TInfo2   uint16
+	TInfo3   uint16
+	TInfo4   uint16
+	TFlags   uint8
+	TInfoS   string   // Font name for character files
+	Comments []string // Lines of the COMNT block, if any
+}
+
+// Parse reads the SAUCE record and comments at the end of data.
+// It reports false when the file has no SAUCE record.
+func Parse(data []byte) (*Record, bool) {
+	rec, _ := split(data)
+	return rec, rec != nil
+}
+
+// Strip returns data without its EOF marker, comment block and SAUCE record.
+// Data without a SAUCE record is returned unchanged.
+func Strip(data []byte) []byte {
+	_, end := split(data)
+	return data[:end]
+}
+
+// Split returns the content before the SAUCE trailer and the parsed record
+// (nil when there is none)
+func Split(data []byte) ([]byte, *Record) {
+	rec, end := split(data)
+	return data[:end], rec
+}
+
+// split parses the trailer and returns where the content ends
+func split(data []byte) (*Record, int) {
+	if len(data) < RecordSize {
+		return nil, len(data)
+	}
+	start := len(data) - RecordSize
+	raw := data[start:]
+	if string(raw[:5]) != recordID {
+		return nil, len(data)
+	}
+
+	rec := &Record{
+		Version:  text(raw[5:7]),
+		Title:    text(raw[7:42]),
+		Author:   text(raw[42:62]),
+		Group:    text(raw[62:82]),
+		Date:     text(raw[82:90]),
+		FileSize: binary.LittleEndian.Uint32(raw[90:94]),
+		DataType: DataType(raw[94]),
+		FileType: raw[95],
+		TInfo1:   binary.LittleEndian.Uint16(raw[96:98]),
+		TInfo2:   binary.LittleEndian.Uint16(raw[98:100]),
+		TInfo3:   binary.LittleEndian.Uint16(raw[100:102]),
+		TInfo4:   binary.LittleEndian.Uint16(raw[102:104]),
+		TFlags:   raw[105],
+		TInfoS:   text(raw[106:128]),
+	}
+
+	// The comment block sits directly before the record; ignore the count
+	// if the block isn't actually there
+	if lines := int(raw[104]); lines > 0 {
+		blockStart := start - len(commentID) - lines*CommentLineSize
+		if blockStart >= 0 && string(data[blockStart:blockStart+len(commentID)]) == commentID {
+			block := data[blockStart+len(commentID) : start]
+			for i := 0; i < lines; i++ {
+				rec.Comments = append(rec.Comments, text(block[i*CommentLineSize:(i+1)*CommentLineSize]))
+			}
+			start = blockStart
+		}
+	}
+
+	if start > 0 && data[start-1] == eof {
+		start--
+	}
+	return rec, start
+}
+
+// text decodes a fixed-size field, dropping trailing spaces and NULs
+func text(field []byte) string {
+	if i := bytes.IndexByte(field, 0); i >= 0 {
+		field = field[:i]
+	}
+	return strings.TrimRight(string(field), " ")
+}
+
+// Time returns the record's date, or false when it isn't a valid CCYYMMDD date
+func (r *Record) Time() (time.Time, bool) {
+	t, err := time.Parse("20060102", r.Date)
+	return t, err == nil
+}
+
+// Width returns the width in characters for text art, or 0 when unknown
+func (r *Record) Width() int {
+	switch r.DataType {
+	case DataCharacter, DataXBin:
+		return int(r.TInfo1)
+	case DataBinaryText:
+		return int(r.FileType) * 2 // BinaryText stores half the width in FileType
+	}
+	return 0
+}
+
+// Height returns the height in lines for text art, or 0 when unknown
+func (r *Record) Height() int {
+	switch r.DataType {
+	case DataCharacter, DataXBin:
+		return int(r.TInfo2)
+	}
+	return 0
+}
+
+// ICE reports whether the art uses iCE colors (high-intensity backgrounds instead of blink)
+func (r *Record) ICE() bool {
+	return r.hasFlags() && r.TFlags&0x01 != 0
+}
+
+// LetterSpacing returns the font width the art was drawn for: 8 or 9 pixels,
+// or 0 when not specified
+func (r *Record) LetterSpacing() int {
+	if !r.hasFlags() {
+		return 0
+	}
+	switch (r.TFlags >> 1) & 0x03 {
+	case 1:
+		return 8
+	case 2:
+		return 9
+	}
+	return 0
+}
+
+// Font returns the font name for text art (TInfoS), such as "IBM VGA"
+func (r *Record) Font() string {
+	if !r.hasFlags() {
+		return ""
+	}
+	return r.TInfoS
+}
+
+// hasFlags reports whether TFlags and TInfoS carry text-mode settings
+func (r *Record) hasFlags() bool {
+	return r.DataType == DataCharacter || r.DataType == DataBinaryText
+}
+
+// Credit returns "title by author/group" with whatever parts are present.
+// Placeholders such as "n/a" are left out.
+func (r *Record) Credit() string {
+	var by []string
+	if !placeholder(r.Author) {
+		by = append(by, r.Author)
+	}
+	if !placeholder(r.Group) {
+		by = append(by, r.Group)
+	}
+	switch {
+	case r.Title != "" && len(by) > 0:
+		return r.Title + " by " + strings.Join(by, "/")
+	case r.Title != "":
+		return r.Title
+	case len(by) > 0:
+		return "by " + strings.Join(by, "/")
+	}
+	return ""
+}
+
+// placeholder reports whether a text field is empty or a stand-in for nothing
+func placeholder(s string) bool {
+	switch strings.ToLower(s) {
+	case "", "n/a", "na", "none", "-", "unknown":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package sauce
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// field pads s with spaces to n bytes
+func field(s string, n int) []byte {
+	return append([]byte(s), bytes.Repeat([]byte(" "), n-len(s))...)
+}
+
+// record builds a SAUCE trailer (comment block and record) for a character file
+func record(title, author, group string, width, height uint16, flags byte, font string, comments ...string) []byte {
+	var b bytes.Buffer
+	if len(comments) > 0 {
+		b.WriteString(commentID)
+		for _, c := range comments {
+			b.Write(field(c, CommentLineSize))
+		}
+	}
+	b.WriteString("SAUCE00")
+	b.Write(field(title, 35))
+	b.Write(field(author, 20))
+	b.Write(field(group, 20))
+	b.WriteString("20241129")
+	binary.Write(&b, binary.LittleEndian, uint32(1234))
+	b.WriteByte(byte(DataCharacter))
+	b.WriteByte(FileANSi)
+	binary.Write(&b, binary.LittleEndian, [4]uint16{width, height, 0, 0})
+	b.WriteByte(byte(len(comments)))
+	b.WriteByte(flags)
+	fontField := make([]byte, 22)
+	copy(fontField, font)
+	b.Write(fontField)
+	return b.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	art := []byte("\x1b[1;31mHello COMNT SAUCE00 world\r\n")
+	data := append(append(art, eof), record("Holodeck Christmas", "Darkman", "Mistigris", 80, 24, 0x05, "IBM VGA", "line one", "line two")...)
+
+	rec, ok := Parse(data)
+	if !ok {
+		t.Fatal("Parse() found no record")
+	}
+	if rec.Title != "Holodeck Christmas" || rec.Author != "Darkman" || rec.Group != "Mistigris" {
+		t.Errorf("text fields = %q %q %q", rec.Title, rec.Author, rec.Group)
+	}
+	if rec.FileSize != 1234 || rec.DataType != DataCharacter || rec.FileType != FileANSi {
+		t.Errorf("type fields = %d %d %d", rec.FileSize, rec.DataType, rec.FileType)
+	}
+	if rec.Width() != 80 || rec.Height() != 24 {
+		t.Errorf("size = %dx%d, want 80x24", rec.Width(), rec.Height())
+	}
+	if !rec.ICE() || rec.LetterSpacing() != 9 || rec.Font() != "IBM VGA" {
+		t.Errorf("flags: ICE %v, spacing %d, font %q", rec.ICE(), rec.LetterSpacing(), rec.Font())
+	}
+	if date, ok := rec.Time(); !ok || date.Year() != 2024 || date.Day() != 29 {
+		t.Errorf("Time() = %v, %v", date, ok)
+	}
+	if want := []string{"line one", "line two"}; !reflect.DeepEqual(rec.Comments, want) {
+		t.Errorf("Comments = %q, want %q", rec.Comments, want)
+	}
+	if got := rec.Credit(); got != "Holodeck Christmas by Darkman/Mistigris" {
+		t.Errorf("Credit() = %q", got)
+	}
+
+	// Markers inside the art must not cut it short
+	if got := Strip(data); !bytes.Equal(got, art) {
+		t.Errorf("Strip() = %q, want %q", got, art)
+	}
+}
+
+func TestNoRecord(t *testing.T) {
+	art := []byte("plain art with the word SAUCE00 in it\x1a")
+	if _, ok := Parse(art); ok {
+		t.Error("Parse() found a record in a file without one")
+	}
+	if got := Strip(art); !bytes.Equal(got, art) {
+		t.Errorf("Strip() changed a file without SAUCE: %q", got)
+	}
+}
+
+func TestMissingCommentBlock(t *testing.T) {
+	// A comment count with no COMNT block keeps the content intact
+	art := []byte("art")
+	trailer := record("T", "", "n/a", 80, 25, 0, "")
+	trailer[RecordSize-24] = 2 // Comments field
+	data := append(append(art, eof), trailer...)
+
+	content, rec := Split(data)
+	if rec == nil || len(rec.Comments) != 0 {
+		t.Fatalf("record = %+v", rec)
+	}
+	if !bytes.Equal(content, art) {
+		t.Errorf("content = %q, want %q", content, art)
+	}
+	if got := rec.Credit(); got != "T" {
+		t.Errorf("Credit() = %q", got)
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Handler runs one caller's session. The connection is closed when it returns.
+type Handler func(sess *Session)
+
+// Session is a caller connected to one of the built-in servers.
+// Reads return the caller's keystrokes with protocol framing removed
+// and writes go to their terminal unchanged (raw CP437).
+type Session struct {
+	Node       int    // Node number handed out by Nodes
+	Protocol   string // "telnet", "rlogin", "ssh" or "web"
+	RemoteAddr string
+	Username   string // Login name sent by rlogin and SSH clients, empty for telnet
+	// Authenticated is set when the server verified the caller is Username:
+	// SSH logins with a key that ssh_authorized_keys lists for that name.
+	// Other usernames are whatever the client chose to send.
+	Authenticated bool
+	Terminal      string // Terminal type if the client reported one
+
+	rw       io.ReadWriter
+	closer   io.Closer
+	readLock sync.Mutex // Protocol parsers keep state between reads
+
+	lock          sync.Mutex
+	width, height int
+	onResize      func(width, height int)
+}
+
+// Read reads the caller's input
+func (s *Session) Read(p []byte) (int, error) {
+	s.readLock.Lock()
+	defer s.readLock.Unlock()
+	return s.rw.Read(p)
+}
+
+// Write sends output to the caller's terminal
+func (s *Session) Write(p []byte) (int, error) {
+	return s.rw.Write(p)
+}
+
+// Close hangs up on the caller
+func (s *Session) Close() error {
+	return s.closer.Close()
+}
+
+// Size returns the terminal size reported by the client, or 0, 0 if unknown
+func (s *Session) Size() (width, height int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.width, s.height
+}
+
+// SetSize records the caller's terminal size, calling the OnResize function
+// when it changes
+func (s *Session) SetSize(width, height int) {
+	s.lock.Lock()
+	changed := width != s.width || height != s.height
+	s.width, s.height = width, height
+	onResize := s.onResize
+	s.lock.Unlock()
+
+	if changed && onResize != nil {
+		onResize(width, height)
+	}
+}
+
+// OnResize sets a function called (from the server's goroutine) when the
+// caller's terminal changes size, such as an SSH window-change
+func (s *Session) OnResize(fn func(width, height int)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onResize = fn
+}
+
+// Nodes hands out node numbers and caps the number of concurrent callers.
+// One Nodes can be shared by several listeners.
+type Nodes struct {
+	inUse []bool
+	lock  sync.Mutex
+}
+
+// NewNodes creates a pool of max nodes numbered from 1
+func NewNodes(max int) *Nodes {
+	return &Nodes{inUse: make([]bool, max)}
+}
+
+// Acquire reserves the lowest free node number. Returns false when all nodes are busy.
+func (n *Nodes) Acquire() (int, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for i, busy := range n.inUse {
+		if !busy {
+			n.inUse[i] = true
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// Release frees a node number returned by Acquire
+func (n *Nodes) Release(node int) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if node >= 1 && node <= len(n.inUse) {
+		n.inUse[node-1] = false
+	}
+}
+
+// Active returns the number of nodes in use
+func (n *Nodes) Active() int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	count := 0
+	for _, busy := range n.inUse {
+		if busy {
+			count++
+		}
+	}
+	return count
+}
+
+// readWriter joins a separate reader and writer
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
+
+// idleReader calls onIdle when no input has arrived for the timeout,
+// for transports where read deadlines can't be used
+type idleReader struct {
+	r       io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+// newIdleReader starts the idle timer. A zero timeout never fires.
+func newIdleReader(r io.Reader, timeout time.Duration, onIdle func()) *idleReader {
+	ir := &idleReader{r: r, timeout: timeout}
+	if timeout > 0 {
+		ir.timer = time.AfterFunc(timeout, onIdle)
+	}
+	return ir
+}
+
+// Read reads input and restarts the idle timer
+func (ir *idleReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	if n > 0 && ir.timer != nil {
+		ir.timer.Reset(ir.timeout)
+	}
+	return n, err
+}
+
+// Stop cancels the idle timer
+func (ir *idleReader) Stop() {
+	if ir.timer != nil {
+		ir.timer.Stop()
+	}
+}
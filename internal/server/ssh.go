@@ -0,0 +1,335 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSetupTimeout bounds the SSH handshake and the wait for a shell request
+const sshSetupTimeout = 30 * time.Second
+
+// SSHServer accepts SSH callers and runs a session for each interactive shell.
+// With no authorized keys every caller gets in as a guest under the name they
+// connected with; otherwise only the listed public keys are accepted, each
+// for its own login name.
+type SSHServer struct {
+	Addr           string
+	Nodes          *Nodes
+	IdleTimeout    time.Duration // Hang up on callers that send nothing for this long (0 = never)
+	HostKey        ssh.Signer
+	AuthorizedKeys []AuthorizedKey // Empty = anonymous guest logins
+	Handler        Handler
+
+	listener net.Listener
+	lock     sync.Mutex
+}
+
+// LoadOrCreateHostKey reads a PEM host key, generating and saving a new
+// ed25519 key when the file does not exist yet
+func LoadOrCreateHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse host key %s: %w", path, err)
+		}
+		return signer, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read host key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate host key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode host key: %w", err)
+	}
+	data = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save host key: %w", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{
+		"path":        path,
+		"fingerprint": ssh.FingerprintSHA256(signer.PublicKey()),
+	}).Info("Generated new SSH host key")
+	return signer, nil
+}
+
+// AuthorizedKey is a public key allowed to log in, and the one login name it
+// is accepted for
+type AuthorizedKey struct {
+	Key  ssh.PublicKey
+	User string
+}
+
+// LoadAuthorizedKeys reads an OpenSSH authorized_keys file. Each key's comment
+// is the login name (alias) it logs in as; a key without one is an error.
+func LoadAuthorizedKeys(path string) ([]AuthorizedKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorized keys: %w", err)
+	}
+
+	var keys []AuthorizedKey
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, comment, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		user := strings.TrimSpace(comment)
+		if user == "" {
+			return nil, fmt.Errorf("%s: key %s has no comment naming the user it logs in as", path, ssh.FingerprintSHA256(key))
+		}
+		keys = append(keys, AuthorizedKey{Key: key, User: user})
+		data = rest
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s contains no keys", path)
+	}
+	return keys, nil
+}
+
+// ListenAndServe listens on Addr and serves callers until Close is called
+func (s *SSHServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts callers on listener until Close is called
+func (s *SSHServer) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+
+	sshConfig := s.serverConfig()
+	logrus.WithFields(logrus.Fields{
+		"addr":        listener.Addr().String(),
+		"fingerprint": ssh.FingerprintSHA256(s.HostKey.PublicKey()),
+		"guest":       len(s.AuthorizedKeys) == 0,
+	}).Info("SSH server listening")
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("accept failed: %w", err)
+		}
+		go s.handleConn(conn, sshConfig)
+	}
+}
+
+// Close stops accepting new callers. Sessions already running are left alone.
+func (s *SSHServer) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// serverConfig builds the SSH server settings for the configured login mode
+func (s *SSHServer) serverConfig() *ssh.ServerConfig {
+	sshConfig := &ssh.ServerConfig{}
+	if len(s.AuthorizedKeys) == 0 {
+		sshConfig.NoClientAuth = true
+	} else {
+		sshConfig.PublicKeyCallback = func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			// A key only logs in as its own user, so one caller can't
+			// take another's alias (and their remembered visits)
+			marshaled := key.Marshal()
+			for _, allowed := range s.AuthorizedKeys {
+				if bytes.Equal(allowed.Key.Marshal(), marshaled) && strings.EqualFold(allowed.User, meta.User()) {
+					return &ssh.Permissions{
+						Extensions: map[string]string{"pubkey-fp": ssh.FingerprintSHA256(key)},
+					}, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown public key for %q", meta.User())
+		}
+	}
+	sshConfig.AddHostKey(s.HostKey)
+	return sshConfig
+}
+
+// handleConn performs the SSH handshake and serves the caller's first shell
+func (s *SSHServer) handleConn(conn net.Conn, sshConfig *ssh.ServerConfig) {
+	defer conn.Close()
+
+	log := logrus.WithField("remote", conn.RemoteAddr().String())
+
+	conn.SetDeadline(time.Now().Add(sshSetupTimeout))
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, sshConfig)
+	if err != nil {
+		log.WithError(err).Debug("SSH handshake failed")
+		return
+	}
+	defer serverConn.Close()
+	conn.SetDeadline(time.Time{})
+	go ssh.DiscardRequests(requests)
+
+	log = log.WithField("user", serverConn.User())
+
+	served := false
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only interactive sessions are supported")
+			continue
+		}
+		if served {
+			newChannel.Reject(ssh.Prohibited, "one session per connection")
+			continue
+		}
+		served = true
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			log.WithError(err).Warn("Failed to accept SSH channel")
+			return
+		}
+
+		// Run the session off the accept loop so further channel
+		// requests can still be refused while it runs
+		go func() {
+			s.serveChannel(serverConn, channel, channelRequests, log)
+			serverConn.Close()
+		}()
+	}
+}
+
+// ptyRequest is the payload of a "pty-req" channel request (RFC 4254 6.2)
+type ptyRequest struct {
+	Term     string
+	Columns  uint32
+	Rows     uint32
+	WidthPx  uint32
+	HeightPx uint32
+	Modes    string
+}
+
+// windowChange is the payload of a "window-change" request (RFC 4254 6.7)
+type windowChange struct {
+	Columns  uint32
+	Rows     uint32
+	WidthPx  uint32
+	HeightPx uint32
+}
+
+// serveChannel waits for the caller's shell request and runs the handler on the channel
+func (s *SSHServer) serveChannel(serverConn *ssh.ServerConn, channel ssh.Channel, requests <-chan *ssh.Request, log *logrus.Entry) {
+	defer channel.Close()
+
+	idle := newIdleReader(channel, s.IdleTimeout, func() { serverConn.Close() })
+	defer idle.Stop()
+
+	sess := &Session{
+		Protocol:   "ssh",
+		RemoteAddr: serverConn.RemoteAddr().String(),
+		Username:   serverConn.User(),
+		// Only PublicKeyCallback grants permissions; guests get none
+		Authenticated: serverConn.Permissions != nil && serverConn.Permissions.Extensions["pubkey-fp"] != "",
+		rw:            &readWriter{Reader: idle, Writer: channel},
+		closer:        serverConn,
+	}
+
+	shell := make(chan bool, 1)
+	go func() {
+		shellStarted := false
+		for req := range requests {
+			ok := false
+			switch req.Type {
+			case "pty-req":
+				var pty ptyRequest
+				if err := ssh.Unmarshal(req.Payload, &pty); err == nil {
+					sess.Terminal = pty.Term
+					sess.SetSize(int(pty.Columns), int(pty.Rows))
+					ok = true
+				}
+			case "window-change":
+				var wc windowChange
+				if err := ssh.Unmarshal(req.Payload, &wc); err == nil {
+					sess.SetSize(int(wc.Columns), int(wc.Rows))
+					ok = true
+				}
+			case "env":
+				ok = true // Accepted and ignored
+			case "shell":
+				if !shellStarted {
+					shellStarted = true
+					ok = true
+					shell <- true
+				}
+			}
+			if req.WantReply {
+				req.Reply(ok, nil)
+			}
+		}
+		if !shellStarted {
+			shell <- false // Channel closed before asking for a shell
+		}
+	}()
+
+	select {
+	case started := <-shell:
+		if !started {
+			return
+		}
+	case <-time.After(sshSetupTimeout):
+		log.Debug("SSH caller never requested a shell")
+		return
+	}
+
+	node, ok := s.Nodes.Acquire()
+	if !ok {
+		log.Warn("All nodes busy, refusing caller")
+		channel.Write([]byte(busyMessage))
+		return
+	}
+	defer s.Nodes.Release(node)
+	sess.Node = node
+
+	width, height := sess.Size()
+	log = log.WithFields(logrus.Fields{
+		"node":     node,
+		"protocol": sess.Protocol,
+		"width":    width,
+		"height":   height,
+	})
+	log.Info("Caller connected")
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithField("panic", r).Error("Session crashed")
+		}
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+		log.Info("Caller disconnected")
+	}()
+
+	s.Handler(sess)
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startSSH runs an SSH server with a fresh host key on a random local port
+func startSSH(t *testing.T, authorized []AuthorizedKey, handler Handler) string {
+	t.Helper()
+	hostKey, err := LoadOrCreateHostKey(filepath.Join(t.TempDir(), "host_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &SSHServer{
+		Nodes:          NewNodes(2),
+		HostKey:        hostKey,
+		AuthorizedKeys: authorized,
+		Handler:        handler,
+	}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+	return listener.Addr().String()
+}
+
+func newClientKey(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestSSHGuestSession(t *testing.T) {
+	addr := startSSH(t, nil, func(sess *Session) {
+		resized := make(chan string, 1)
+		sess.OnResize(func(w, h int) { resized <- fmt.Sprintf("resized %dx%d", w, h) })
+		w, h := sess.Size()
+		fmt.Fprintf(sess, "%s %s %s %dx%d node %d authenticated %t\r\n",
+			sess.Protocol, sess.Username, sess.Terminal, w, h, sess.Node, sess.Authenticated)
+		line, _ := bufio.NewReader(sess).ReadString('\r')
+		fmt.Fprintf(sess, "got %q\r\n", line)
+		fmt.Fprintf(sess, "%s\r\n", <-resized)
+	})
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "snowqueen",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	if err := session.RequestPty("ansi", 40, 100, ssh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	stdin, _ := session.StdinPipe()
+	stdout, _ := session.StdoutPipe()
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(stdout)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ssh snowqueen ansi 100x40 node 1 authenticated false\r\n"; line != want {
+		t.Errorf("first line = %q, want %q", line, want)
+	}
+
+	stdin.Write([]byte("hi\r"))
+	line, _ = reader.ReadString('\n')
+	if want := "got \"hi\\r\"\r\n"; line != want {
+		t.Errorf("second line = %q, want %q", line, want)
+	}
+
+	// A window-change reaches the running session
+	if err := session.WindowChange(30, 90); err != nil {
+		t.Fatal(err)
+	}
+	line, _ = reader.ReadString('\n')
+	if want := "resized 90x30\r\n"; line != want {
+		t.Errorf("after window-change = %q, want %q", line, want)
+	}
+}
+
+func TestSSHAuthorizedKeys(t *testing.T) {
+	allowed := newClientKey(t)
+	addr := startSSH(t, []AuthorizedKey{{Key: allowed.PublicKey(), User: "Sysop"}}, func(sess *Session) {})
+
+	dial := func(user string, signer ssh.Signer) error {
+		client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         5 * time.Second,
+		})
+		if err == nil {
+			client.Close()
+		}
+		return err
+	}
+
+	if err := dial("sysop", allowed); err != nil {
+		t.Errorf("listed key rejected: %v", err)
+	}
+	if err := dial("sysop", newClientKey(t)); err == nil {
+		t.Error("unlisted key was accepted")
+	}
+	if err := dial("snowqueen", allowed); err == nil {
+		t.Error("listed key was accepted for another user")
+	}
+}
+
+func TestLoadOrCreateHostKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "host_key")
+
+	first, err := LoadOrCreateHostKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("host key not saved: %v", err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		t.Errorf("host key permissions = %v, want owner-only", info.Mode().Perm())
+	}
+
+	second, err := LoadOrCreateHostKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ssh.FingerprintSHA256(first.PublicKey()) != ssh.FingerprintSHA256(second.PublicKey()) {
+		t.Error("reloaded host key differs from the saved one")
+	}
+}
+
+func TestLoadAuthorizedKeys(t *testing.T) {
+	key := newClientKey(t)
+	path := filepath.Join(t.TempDir(), "authorized_keys")
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key.PublicKey())))
+	data := "# sysop keys\n" + line + " sysop\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadAuthorizedKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].User != "sysop" || ssh.FingerprintSHA256(keys[0].Key) != ssh.FingerprintSHA256(key.PublicKey()) {
+		t.Errorf("LoadAuthorizedKeys() = %+v", keys)
+	}
+
+	// A key must name the user it logs in as
+	if err := os.WriteFile(path, []byte(line+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAuthorizedKeys(path); err == nil {
+		t.Error("LoadAuthorizedKeys() accepted a key without a user")
+	}
+}
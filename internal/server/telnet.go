@@ -0,0 +1,501 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Telnet commands (RFC 854)
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+)
+
+// Telnet options we negotiate
+const (
+	optBinary = 0  // RFC 856
+	optEcho   = 1  // RFC 857
+	optSGA    = 3  // RFC 858, suppress go-ahead
+	optNAWS   = 31 // RFC 1073, window size
+)
+
+const (
+	// negotiationTimeout is how long we wait for the client to report its window size
+	negotiationTimeout = time.Second
+	// rloginWait is how long we wait for the NUL byte that starts an rlogin handshake
+	rloginWait = 500 * time.Millisecond
+)
+
+// busyMessage is sent to callers when every node is taken
+const busyMessage = "\r\nAll nodes are busy. Please try again later.\r\n"
+
+// TelnetServer accepts telnet callers, and rlogin callers on the same port,
+// and runs a session for each in its own goroutine
+type TelnetServer struct {
+	Addr        string
+	Nodes       *Nodes
+	IdleTimeout time.Duration // Hang up on callers that send nothing for this long (0 = never)
+	Handler     Handler
+
+	listener net.Listener
+	lock     sync.Mutex
+}
+
+// ListenAndServe listens on Addr and serves callers until Close is called
+func (s *TelnetServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts callers on listener until Close is called
+func (s *TelnetServer) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+
+	logrus.WithField("addr", listener.Addr().String()).Info("Telnet server listening")
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("accept failed: %w", err)
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close stops accepting new callers. Sessions already running are left alone.
+func (s *TelnetServer) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// handleConn sets up one connection and runs the handler for it
+func (s *TelnetServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	log := logrus.WithField("remote", conn.RemoteAddr().String())
+
+	node, ok := s.Nodes.Acquire()
+	if !ok {
+		log.Warn("All nodes busy, refusing caller")
+		conn.Write([]byte(busyMessage))
+		return
+	}
+	defer s.Nodes.Release(node)
+
+	sess, err := s.newSession(conn)
+	if err != nil {
+		log.WithError(err).Warn("Connection setup failed")
+		return
+	}
+	sess.Node = node
+	sess.RemoteAddr = conn.RemoteAddr().String()
+
+	width, height := sess.Size()
+	log = log.WithFields(logrus.Fields{
+		"node":     node,
+		"protocol": sess.Protocol,
+		"user":     sess.Username,
+		"width":    width,
+		"height":   height,
+	})
+	log.Info("Caller connected")
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithField("panic", r).Error("Session crashed")
+		}
+		log.Info("Caller disconnected")
+	}()
+
+	s.Handler(sess)
+}
+
+// newSession works out whether the caller speaks rlogin or telnet and
+// performs the matching handshake
+func (s *TelnetServer) newSession(conn net.Conn) (*Session, error) {
+	// rlogin clients speak first with a NUL byte; telnet clients either send
+	// option negotiation or wait for us
+	var first []byte
+	buf := make([]byte, 512)
+	conn.SetReadDeadline(time.Now().Add(rloginWait))
+	n, err := conn.Read(buf)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil, err
+	}
+	first = buf[:n]
+
+	if len(first) > 0 && first[0] == 0 {
+		rc := &rloginConn{conn: conn, idleTimeout: s.IdleTimeout}
+		sess := &Session{Protocol: "rlogin", rw: rc, closer: conn}
+		if err := rc.handshake(first, sess); err != nil {
+			return nil, fmt.Errorf("rlogin handshake failed: %w", err)
+		}
+		return sess, nil
+	}
+
+	tc := newTelnetConn(conn, s.IdleTimeout)
+	sess := &Session{Protocol: "telnet", rw: tc, closer: conn}
+	tc.sess = sess
+	if err := tc.negotiate(first); err != nil {
+		return nil, fmt.Errorf("telnet negotiation failed: %w", err)
+	}
+	return sess, nil
+}
+
+// Parser states for the telnet input stream
+const (
+	stateData = iota
+	stateIAC
+	stateOption // Waiting for the option byte after WILL/WONT/DO/DONT
+	stateSB
+	stateSBIAC
+)
+
+// telnetConn strips telnet commands from the input stream, answers option
+// negotiation and escapes IAC bytes in the output
+type telnetConn struct {
+	conn        net.Conn
+	sess        *Session
+	idleTimeout time.Duration
+
+	// Option state: local = options we perform, remote = options the client performs.
+	// pending marks a request we sent and are waiting to hear back about.
+	local, remote               [256]bool
+	pendingLocal, pendingRemote [256]bool
+	nawsDone                    bool
+
+	state   int
+	command byte
+	sb      []byte
+	lastCR  bool   // Previous data byte was CR, so a following LF or NUL is dropped
+	pending []byte // Data read during negotiation, returned by the next Read
+
+	writeLock sync.Mutex
+}
+
+func newTelnetConn(conn net.Conn, idleTimeout time.Duration) *telnetConn {
+	return &telnetConn{conn: conn, idleTimeout: idleTimeout}
+}
+
+// supportedLocal reports whether we agree to perform an option
+func supportedLocal(opt byte) bool {
+	return opt == optEcho || opt == optSGA || opt == optBinary
+}
+
+// supportedRemote reports whether we want the client to perform an option
+func supportedRemote(opt byte) bool {
+	return opt == optSGA || opt == optBinary || opt == optNAWS
+}
+
+// negotiate sends our option requests and waits briefly for the window size.
+// first holds any bytes the client sent before we spoke.
+func (t *telnetConn) negotiate(first []byte) error {
+	// We echo (i.e. the client must not), run character-at-a-time and 8-bit clean
+	var req []byte
+	for _, opt := range []byte{optEcho, optSGA, optBinary} {
+		t.pendingLocal[opt] = true
+		req = append(req, telnetIAC, telnetWILL, opt)
+	}
+	for _, opt := range []byte{optSGA, optBinary, optNAWS} {
+		t.pendingRemote[opt] = true
+		req = append(req, telnetIAC, telnetDO, opt)
+	}
+
+	t.pending = append(t.pending, t.process(first)...)
+	if err := t.writeRaw(req); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(negotiationTimeout)
+	buf := make([]byte, 512)
+	for !t.nawsDone && time.Now().Before(deadline) {
+		t.conn.SetReadDeadline(deadline)
+		n, err := t.conn.Read(buf)
+		t.pending = append(t.pending, t.process(buf[:n])...)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			return err
+		}
+	}
+	t.conn.SetReadDeadline(time.Time{})
+
+	if !t.nawsDone {
+		logrus.Debug("Telnet client did not report its window size")
+	}
+	return nil
+}
+
+// Read returns the caller's input with telnet commands removed
+func (t *telnetConn) Read(p []byte) (int, error) {
+	if len(t.pending) > 0 {
+		n := copy(p, t.pending)
+		t.pending = t.pending[n:]
+		return n, nil
+	}
+
+	buf := make([]byte, len(p))
+	for {
+		if t.idleTimeout > 0 {
+			t.conn.SetReadDeadline(time.Now().Add(t.idleTimeout))
+		}
+		n, err := t.conn.Read(buf)
+		data := t.process(buf[:n])
+		if len(data) > 0 {
+			// data is never longer than what was read, so it fits in p
+			return copy(p, data), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write sends output to the caller, doubling any IAC bytes
+func (t *telnetConn) Write(p []byte) (int, error) {
+	if bytes.IndexByte(p, telnetIAC) < 0 {
+		if err := t.writeRaw(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	escaped := bytes.ReplaceAll(p, []byte{telnetIAC}, []byte{telnetIAC, telnetIAC})
+	if err := t.writeRaw(escaped); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeRaw writes bytes to the socket unmodified
+func (t *telnetConn) writeRaw(p []byte) error {
+	t.writeLock.Lock()
+	defer t.writeLock.Unlock()
+	_, err := t.conn.Write(p)
+	return err
+}
+
+// process runs received bytes through the telnet parser and returns the data bytes
+func (t *telnetConn) process(in []byte) []byte {
+	out := make([]byte, 0, len(in))
+
+	for _, b := range in {
+		switch t.state {
+		case stateData:
+			if b == telnetIAC {
+				t.state = stateIAC
+				continue
+			}
+			// Telnet sends Enter as CR LF or CR NUL; pass on just the CR
+			if t.lastCR && (b == '\n' || b == 0) {
+				t.lastCR = false
+				continue
+			}
+			t.lastCR = b == '\r'
+			out = append(out, b)
+
+		case stateIAC:
+			switch b {
+			case telnetIAC:
+				out = append(out, telnetIAC) // Escaped 0xFF data byte
+				t.state = stateData
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				t.command = b
+				t.state = stateOption
+			case telnetSB:
+				t.sb = t.sb[:0]
+				t.state = stateSB
+			default:
+				// NOP, GA, AYT and friends need no answer
+				t.state = stateData
+			}
+
+		case stateOption:
+			t.handleOption(t.command, b)
+			t.state = stateData
+
+		case stateSB:
+			if b == telnetIAC {
+				t.state = stateSBIAC
+			} else {
+				t.sb = append(t.sb, b)
+			}
+
+		case stateSBIAC:
+			switch b {
+			case telnetSE:
+				t.handleSubnegotiation(t.sb)
+				t.state = stateData
+			case telnetIAC:
+				t.sb = append(t.sb, telnetIAC)
+				t.state = stateSB
+			default:
+				// Malformed subnegotiation - drop it
+				t.state = stateData
+			}
+		}
+	}
+
+	return out
+}
+
+// handleOption answers WILL/WONT/DO/DONT from the client.
+// Replies are only sent when our state changes, so negotiation cannot loop.
+func (t *telnetConn) handleOption(command, opt byte) {
+	var reply []byte
+
+	switch command {
+	case telnetDO:
+		if t.pendingLocal[opt] {
+			t.pendingLocal[opt] = false
+			t.local[opt] = true
+		} else if !t.local[opt] {
+			if supportedLocal(opt) {
+				t.local[opt] = true
+				reply = []byte{telnetIAC, telnetWILL, opt}
+			} else {
+				reply = []byte{telnetIAC, telnetWONT, opt}
+			}
+		}
+
+	case telnetDONT:
+		if t.pendingLocal[opt] {
+			t.pendingLocal[opt] = false
+		} else if t.local[opt] {
+			reply = []byte{telnetIAC, telnetWONT, opt}
+		}
+		t.local[opt] = false
+
+	case telnetWILL:
+		if t.pendingRemote[opt] {
+			t.pendingRemote[opt] = false
+			t.remote[opt] = true
+		} else if !t.remote[opt] {
+			if supportedRemote(opt) {
+				t.remote[opt] = true
+				reply = []byte{telnetIAC, telnetDO, opt}
+			} else {
+				reply = []byte{telnetIAC, telnetDONT, opt}
+			}
+		}
+
+	case telnetWONT:
+		if t.pendingRemote[opt] {
+			t.pendingRemote[opt] = false
+		} else if t.remote[opt] {
+			reply = []byte{telnetIAC, telnetDONT, opt}
+		}
+		t.remote[opt] = false
+		if opt == optNAWS {
+			t.nawsDone = true // Client will not tell us its size
+		}
+	}
+
+	if reply != nil {
+		if err := t.writeRaw(reply); err != nil {
+			logrus.WithError(err).Debug("Failed to send telnet option reply")
+		}
+	}
+}
+
+// handleSubnegotiation handles IAC SB ... IAC SE payloads
+func (t *telnetConn) handleSubnegotiation(sb []byte) {
+	if len(sb) == 5 && sb[0] == optNAWS {
+		width := int(sb[1])<<8 | int(sb[2])
+		height := int(sb[3])<<8 | int(sb[4])
+		t.nawsDone = true
+		if width > 0 && height > 0 && t.sess != nil {
+			t.sess.SetSize(width, height)
+			logrus.WithFields(logrus.Fields{
+				"width":  width,
+				"height": height,
+			}).Debug("Telnet window size received")
+		}
+	}
+}
+
+// rloginConn is a plain byte stream after the rlogin handshake
+type rloginConn struct {
+	conn        net.Conn
+	idleTimeout time.Duration
+	pending     []byte // Input that arrived along with the greeting
+}
+
+// handshake reads the client's "\0user\0user\0term/speed\0" greeting
+// (first holds what has already been read) and accepts it
+func (r *rloginConn) handshake(first []byte, sess *Session) error {
+	greeting := append([]byte(nil), first...)
+	buf := make([]byte, 256)
+	r.conn.SetReadDeadline(time.Now().Add(negotiationTimeout))
+	defer r.conn.SetReadDeadline(time.Time{})
+
+	// Leading NUL plus three NUL-terminated strings
+	for bytes.Count(greeting, []byte{0}) < 4 {
+		if len(greeting) > 1024 {
+			return errors.New("greeting too long")
+		}
+		n, err := r.conn.Read(buf)
+		greeting = append(greeting, buf[:n]...)
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+
+	fields := strings.SplitN(string(greeting[1:]), "\x00", 4)
+	sess.Username = fields[1] // Name on this server
+	sess.Terminal, _, _ = strings.Cut(fields[2], "/")
+	r.pending = []byte(fields[3])
+
+	// A single NUL tells the client the handshake succeeded
+	_, err := r.conn.Write([]byte{0})
+	return err
+}
+
+// Read returns the caller's input
+func (r *rloginConn) Read(p []byte) (int, error) {
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	if r.idleTimeout > 0 {
+		r.conn.SetReadDeadline(time.Now().Add(r.idleTimeout))
+	}
+	return r.conn.Read(p)
+}
+
+// Write sends output to the caller
+func (r *rloginConn) Write(p []byte) (int, error) {
+	return r.conn.Write(p)
+}
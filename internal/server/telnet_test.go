@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTelnet runs a telnet server on a random local port
+func startTelnet(t *testing.T, nodes *Nodes, handler Handler) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &TelnetServer{Nodes: nodes, Handler: handler}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+	return listener.Addr().String()
+}
+
+func TestTelnetProcess(t *testing.T) {
+	tc := newTelnetConn(nil, 0)
+	tc.sess = &Session{}
+
+	in := []byte{'a', telnetIAC, telnetIAC, '\r', 0, 'b', '\r', '\n',
+		telnetIAC, 241, // NOP
+		telnetIAC, telnetSB, optNAWS, 0, 132, 0, 50, telnetIAC, telnetSE, 'c'}
+	want := []byte{'a', telnetIAC, '\r', 'b', '\r', 'c'}
+
+	if got := tc.process(in); !bytes.Equal(got, want) {
+		t.Errorf("process() = %v, want %v", got, want)
+	}
+	if w, h := tc.sess.Size(); w != 132 || h != 50 {
+		t.Errorf("Size() = %dx%d, want 132x50", w, h)
+	}
+
+	// Commands split across reads are still recognised
+	tc.process([]byte{telnetIAC, telnetSB, optNAWS, 0})
+	tc.process([]byte{100, 0, 30, telnetIAC})
+	tc.process([]byte{telnetSE})
+	if w, h := tc.sess.Size(); w != 100 || h != 30 {
+		t.Errorf("Size() after split SB = %dx%d, want 100x30", w, h)
+	}
+}
+
+func TestTelnetSession(t *testing.T) {
+	got := make(chan string, 1)
+	addr := startTelnet(t, NewNodes(2), func(sess *Session) {
+		w, h := sess.Size()
+		sess.Write([]byte{'o', 'k', telnetIAC})
+		line, _ := bufio.NewReader(sess).ReadString('\r')
+		got <- fmt.Sprintf("%s %d %d %s", sess.Protocol, w, h, line)
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Answer the server's DO NAWS with our size
+	conn.Write([]byte{telnetIAC, telnetWILL, optNAWS,
+		telnetIAC, telnetSB, optNAWS, 0, 100, 0, 40, telnetIAC, telnetSE})
+
+	// Skip the server's option requests and check the IAC in the output is doubled
+	reply := readUntil(t, conn, []byte{'o', 'k'})
+	if !bytes.HasSuffix(reply, []byte{'o', 'k'}) {
+		t.Fatalf("unexpected output %v", reply)
+	}
+	extra := make([]byte, 2)
+	if _, err := io.ReadFull(conn, extra); err != nil || !bytes.Equal(extra, []byte{telnetIAC, telnetIAC}) {
+		t.Errorf("IAC not escaped: %v %v", extra, err)
+	}
+
+	conn.Write([]byte("hi\r\x00"))
+
+	select {
+	case result := <-got:
+		if result != "telnet 100 40 hi\r" {
+			t.Errorf("handler saw %q", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not finish")
+	}
+}
+
+func TestRloginSession(t *testing.T) {
+	got := make(chan *Session, 1)
+	addr := startTelnet(t, NewNodes(2), func(sess *Session) {
+		buf := make([]byte, 8)
+		n, _ := sess.Read(buf)
+		sess.Terminal += ":" + string(buf[:n])
+		got <- sess
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("\x00jane\x00snowqueen\x00ansi-bbs/38400\x00x"))
+	ack := make([]byte, 1)
+	if _, err := io.ReadFull(conn, ack); err != nil || ack[0] != 0 {
+		t.Fatalf("expected NUL acknowledgement, got %v %v", ack, err)
+	}
+
+	select {
+	case sess := <-got:
+		if sess.Protocol != "rlogin" || sess.Username != "snowqueen" || sess.Terminal != "ansi-bbs:x" {
+			t.Errorf("session = %+v", sess)
+		}
+		if sess.Node != 1 {
+			t.Errorf("Node = %d, want 1", sess.Node)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not run")
+	}
+}
+
+func TestNodeLimit(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	nodes := NewNodes(1)
+	addr := startTelnet(t, nodes, func(sess *Session) {
+		started <- struct{}{}
+		<-release
+	})
+	defer close(release)
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	first.Write([]byte{telnetIAC, telnetWONT, optNAWS})
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("first caller never got a node")
+	}
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, _ := io.ReadAll(second)
+	if !strings.Contains(string(reply), "All nodes are busy") {
+		t.Errorf("second caller got %q", reply)
+	}
+	if nodes.Active() != 1 {
+		t.Errorf("Active() = %d, want 1", nodes.Active())
+	}
+}
+
+// readUntil reads from conn until the output ends with suffix
+func readUntil(t *testing.T, conn net.Conn, suffix []byte) []byte {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var out []byte
+	buf := make([]byte, 1)
+	for !bytes.HasSuffix(out, suffix) {
+		if _, err := conn.Read(buf); err != nil {
+			t.Fatalf("read failed after %v: %v", out, err)
+		}
+		out = append(out, buf[0])
+	}
+	return out
+}
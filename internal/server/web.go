@@ -0,0 +1,249 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+
+	"github.com/robbiew/advent/internal/display"
+	"github.com/robbiew/advent/internal/input"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// webHelloTimeout is how long we wait for the browser to report its terminal size
+const webHelloTimeout = 5 * time.Second
+
+// WebServer serves a browser terminal page and bridges its WebSocket to a session.
+// Output is converted from CP437 to UTF-8; key events from the page are turned
+// into the byte sequences the input handler already understands.
+type WebServer struct {
+	Addr        string
+	Nodes       *Nodes
+	IdleTimeout time.Duration // Hang up on callers that send nothing for this long (0 = never)
+	Handler     Handler
+
+	httpServer *http.Server
+	lock       sync.Mutex
+}
+
+// webMessage is a message from the browser terminal
+type webMessage struct {
+	Type string `json:"type"` // "resize", "key" or "text"
+	Cols int    `json:"cols,omitempty"`
+	Rows int    `json:"rows,omitempty"`
+	Key  string `json:"key,omitempty"`  // Browser KeyboardEvent.key value
+	Text string `json:"text,omitempty"` // Literal input (pasted text, terminal replies)
+}
+
+// browserKeys maps KeyboardEvent.key names to input keys
+var browserKeys = map[string]input.Key{
+	"Escape":     input.KeyEsc,
+	"Enter":      input.KeyEnter,
+	"Backspace":  input.KeyBackspace,
+	"Tab":        input.KeyTab,
+	"ArrowUp":    input.KeyArrowUp,
+	"ArrowDown":  input.KeyArrowDown,
+	"ArrowLeft":  input.KeyArrowLeft,
+	"ArrowRight": input.KeyArrowRight,
+	"PageUp":     input.KeyPageUp,
+	"PageDown":   input.KeyPageDown,
+	"Home":       input.KeyHome,
+	"End":        input.KeyEnd,
+	"Insert":     input.KeyInsert,
+	"Delete":     input.KeyDelete,
+	"F1":         input.KeyF1,
+	"F2":         input.KeyF2,
+	"F3":         input.KeyF3,
+	"F4":         input.KeyF4,
+	"F5":         input.KeyF5,
+	"F6":         input.KeyF6,
+	"F7":         input.KeyF7,
+	"F8":         input.KeyF8,
+	"F9":         input.KeyF9,
+	"F10":        input.KeyF10,
+	"F11":        input.KeyF11,
+	"F12":        input.KeyF12,
+}
+
+// browserKeyBytes returns the bytes a terminal would send for a browser key
+// name, or nil for keys the door has no use for (Shift, Control...)
+func browserKeyBytes(name string) []byte {
+	if key, ok := browserKeys[name]; ok {
+		return []byte(input.KeySequence(key))
+	}
+	if r := []rune(name); len(r) == 1 && input.IsPrintable(r[0]) {
+		return []byte{byte(r[0])}
+	}
+	return nil
+}
+
+// HTTPHandler returns the handler serving the terminal page and its WebSocket
+func (s *WebServer) HTTPHandler() http.Handler {
+	static, _ := fs.Sub(webFiles, "web")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.Handle("/ws", websocket.Handler(s.handleSocket))
+	return mux
+}
+
+// ListenAndServe listens on Addr and serves callers until Close is called
+func (s *WebServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts callers on listener until Close is called
+func (s *WebServer) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	s.httpServer = &http.Server{
+		Handler:           s.HTTPHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	httpServer := s.httpServer
+	s.lock.Unlock()
+
+	logrus.WithField("addr", listener.Addr().String()).Info("Web server listening")
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close stops accepting new callers. Sessions already running are left alone.
+func (s *WebServer) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Close()
+}
+
+// socketWriter sends each write as one text frame
+type socketWriter struct {
+	ws   *websocket.Conn
+	lock sync.Mutex
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if err := websocket.Message.Send(w.ws, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// handleSocket runs one browser caller
+func (s *WebServer) handleSocket(ws *websocket.Conn) {
+	defer ws.Close()
+
+	log := logrus.WithField("remote", ws.Request().RemoteAddr)
+	out := &socketWriter{ws: ws}
+
+	node, ok := s.Nodes.Acquire()
+	if !ok {
+		log.Warn("All nodes busy, refusing caller")
+		out.Write([]byte(busyMessage))
+		return
+	}
+	defer s.Nodes.Release(node)
+
+	// Keystrokes from the page are fed to the session through a pipe
+	inputReader, inputWriter := io.Pipe()
+	idle := newIdleReader(inputReader, s.IdleTimeout, func() { ws.Close() })
+	defer idle.Stop()
+
+	sess := &Session{
+		Node:       node,
+		Protocol:   "web",
+		RemoteAddr: ws.Request().RemoteAddr,
+		rw:         &readWriter{Reader: idle, Writer: display.NewUTF8Writer(out)},
+		closer:     ws,
+	}
+
+	// The page reports its size first; start once we have it
+	ws.SetReadDeadline(time.Now().Add(webHelloTimeout))
+	if msg, err := receiveWebMessage(ws); err == nil {
+		s.handleMessage(sess, msg, inputWriter)
+	}
+	ws.SetReadDeadline(time.Time{})
+
+	go func() {
+		for {
+			msg, err := receiveWebMessage(ws)
+			if err != nil {
+				inputWriter.CloseWithError(io.EOF)
+				return
+			}
+			s.handleMessage(sess, msg, inputWriter)
+		}
+	}()
+
+	width, height := sess.Size()
+	log = log.WithFields(logrus.Fields{
+		"node":     node,
+		"protocol": sess.Protocol,
+		"width":    width,
+		"height":   height,
+	})
+	log.Info("Caller connected")
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithField("panic", r).Error("Session crashed")
+		}
+		log.Info("Caller disconnected")
+	}()
+
+	s.Handler(sess)
+}
+
+// receiveWebMessage reads and decodes the next message from the page
+func receiveWebMessage(ws *websocket.Conn) (webMessage, error) {
+	var raw string
+	var msg webMessage
+	if err := websocket.Message.Receive(ws, &raw); err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		logrus.WithError(err).Debug("Ignoring malformed web terminal message")
+		return webMessage{}, nil
+	}
+	return msg, nil
+}
+
+// handleMessage applies one message from the page to the session
+func (s *WebServer) handleMessage(sess *Session, msg webMessage, inputWriter io.Writer) {
+	switch msg.Type {
+	case "resize":
+		if msg.Cols > 0 && msg.Rows > 0 {
+			sess.SetSize(msg.Cols, msg.Rows)
+		}
+	case "key":
+		if data := browserKeyBytes(msg.Key); data != nil {
+			inputWriter.Write(data)
+		}
+	case "text":
+		if msg.Text != "" {
+			inputWriter.Write([]byte(msg.Text))
+		}
+	}
+}
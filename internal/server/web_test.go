@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSession(t *testing.T) {
+	srv := &WebServer{Nodes: NewNodes(2), Handler: func(sess *Session) {
+		w, h := sess.Size()
+		// \xdb is the CP437 full block
+		fmt.Fprintf(sess, "%s %dx%d node %d \xdb", sess.Protocol, w, h, sess.Node)
+		reader := bufio.NewReader(sess)
+		seq := make([]byte, 3)
+		io.ReadFull(reader, seq)
+		rest, _ := reader.ReadString('\r')
+		fmt.Fprintf(sess, "%q %q", seq, rest)
+	}}
+	httpServer := httptest.NewServer(srv.HTTPHandler())
+	defer httpServer.Close()
+
+	// The page itself is served from /
+	resp, err := http.Get(httpServer.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), "new WebSocket") {
+		t.Errorf("index page not served: %.80q", page)
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+	ws, err := websocket.Dial(wsURL, "", httpServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	websocket.Message.Send(ws, `{"type":"resize","cols":80,"rows":25}`)
+
+	var frame string
+	if err := websocket.Message.Receive(ws, &frame); err != nil {
+		t.Fatal(err)
+	}
+	if want := "web 80x25 node 1 █"; frame != want {
+		t.Errorf("first frame = %q, want %q", frame, want)
+	}
+
+	for _, msg := range []string{
+		`{"type":"key","key":"ArrowUp"}`,
+		`{"type":"key","key":"Shift"}`,
+		`{"type":"key","key":"q"}`,
+		`{"type":"text","text":"ok"}`,
+		`{"type":"key","key":"Enter"}`,
+	} {
+		websocket.Message.Send(ws, msg)
+	}
+
+	if err := websocket.Message.Receive(ws, &frame); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"\x1b[A" "qok\r"`; frame != want {
+		t.Errorf("second frame = %q, want %q", frame, want)
+	}
+}
+
+func TestBrowserKeyBytes(t *testing.T) {
+	testCases := map[string]string{
+		"ArrowLeft": "\x1b[D",
+		"Escape":    "\x1b",
+		"Enter":     "\r",
+		"F1":        "\x1bOP",
+		"m":         "m",
+		"Shift":     "",
+		"é":         "",
+	}
+	for name, want := range testCases {
+		if got := string(browserKeyBytes(name)); got != want {
+			t.Errorf("browserKeyBytes(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// Package store remembers callers between sessions: when they visited, which
+// days and years they opened and where they left off. Everything lives in one
+// small JSON file that every node running the door can update safely.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the data file inside the data directory
+const FileName = "advent-users.json"
+
+// fileVersion is written to the data file so later formats can migrate it
+const fileVersion = 1
+
+// Key identifies a caller: the BBS they called from plus their user record
+// number when the dropfile has one, otherwise their alias
+type Key struct {
+	BBS    string
+	Alias  string
+	Record int
+}
+
+// String returns the key used in the data file, such as "mistigris/#12" or
+// "mistigris/snowqueen". Names are case-insensitive.
+func (k Key) String() string {
+	who := strings.ToLower(strings.TrimSpace(k.Alias))
+	if k.Record > 0 {
+		who = "#" + strconv.Itoa(k.Record)
+	}
+	return strings.ToLower(strings.TrimSpace(k.BBS)) + "/" + who
+}
+
+// Valid reports whether the key names a caller
+func (k Key) Valid() bool {
+	return k.Record > 0 || strings.TrimSpace(k.Alias) != ""
+}
+
+// Position is a screen a caller was looking at
+type Position struct {
+	Year   int    `json:"year"`
+	Day    int    `json:"day,omitempty"`
+	Screen string `json:"screen"`
+}
+
+// Visitor is everything remembered about one caller
+type Visitor struct {
+	Alias      string        `json:"alias"` // Latest alias, for sysops reading the file
+	FirstVisit time.Time     `json:"first_visit"`
+	LastVisit  time.Time     `json:"last_visit"`
+	Visits     int           `json:"visits"`
+	Years      []int         `json:"years,omitempty"` // Years whose calendar was opened, ascending
+	Days       map[int][]int `json:"days,omitempty"`  // Days opened, ascending, by year
+	LastScreen Position      `json:"last_screen"`
+	LastDay    Position      `json:"last_day"`           // Where to resume next time
+	Unlocked   []int         `json:"unlocked,omitempty"` // Years opened with a key, ascending
+}
+
+// Visit records the start of a session at the given time
+func (v *Visitor) Visit(now time.Time) {
+	if v.FirstVisit.IsZero() {
+		v.FirstVisit = now
+	}
+	v.LastVisit = now
+	v.Visits++
+}
+
+// ViewYear records that a year's calendar was opened
+func (v *Visitor) ViewYear(year int) {
+	v.Years = insert(v.Years, year)
+}
+
+// ViewDay records that a day (and so its year) was opened
+func (v *Visitor) ViewDay(year, day int) {
+	v.ViewYear(year)
+	if v.Days == nil {
+		v.Days = make(map[int][]int)
+	}
+	v.Days[year] = insert(v.Days[year], day)
+}
+
+// Viewed reports whether a day has been opened before
+func (v *Visitor) Viewed(year, day int) bool {
+	return contains(v.Days[year], day)
+}
+
+// UnlockYear records that the caller entered a year's key
+func (v *Visitor) UnlockYear(year int) {
+	v.Unlocked = insert(v.Unlocked, year)
+}
+
+// HasUnlocked reports whether the caller has entered a year's key before
+func (v *Visitor) HasUnlocked(year int) bool {
+	return contains(v.Unlocked, year)
+}
+
+// NewestUnopened returns the latest day up to maxDay that hasn't been
+// opened, or 0 when all of them have
+func (v *Visitor) NewestUnopened(year, maxDay int) int {
+	for day := maxDay; day >= 1; day-- {
+		if !v.Viewed(year, day) {
+			return day
+		}
+	}
+	return 0
+}
+
+// contains reports whether a sorted list holds n
+func contains(list []int, n int) bool {
+	i := sort.SearchInts(list, n)
+	return i < len(list) && list[i] == n
+}
+
+// insert adds n to a sorted list unless it is already there
+func insert(list []int, n int) []int {
+	i := sort.SearchInts(list, n)
+	if i < len(list) && list[i] == n {
+		return list
+	}
+	list = append(list, 0)
+	copy(list[i+1:], list[i:])
+	list[i] = n
+	return list
+}
+
+// Presence is a caller in the door right now
+type Presence struct {
+	BBS   string    `json:"bbs,omitempty"`
+	Node  int       `json:"node"`
+	Alias string    `json:"alias"`
+	Where string    `json:"where"` // What they are looking at, such as "2025 day 5"
+	Since time.Time `json:"since"`
+	Seen  time.Time `json:"seen"` // Last activity; callers that hang up without leaving go stale
+}
+
+// key returns the presence entry's key in the data file, such as "mistigris/3"
+func (p Presence) key() string {
+	return strings.ToLower(strings.TrimSpace(p.BBS)) + "/" + strconv.Itoa(p.Node)
+}
+
+// data is the layout of the data file
+type data struct {
+	Version int                  `json:"version"`
+	Users   map[string]*Visitor  `json:"users"`
+	Nodes   map[string]*Presence `json:"nodes,omitempty"`
+}
+
+// Store reads and writes the data file in one directory
+type Store struct {
+	path string
+}
+
+// Open prepares the data directory, creating it if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return &Store{path: filepath.Join(dir, FileName)}, nil
+}
+
+// Path returns the location of the data file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns what is known about a caller; a caller never seen before
+// comes back as a zero Visitor
+func (s *Store) Get(key Key) (Visitor, error) {
+	d, err := s.load()
+	if err != nil {
+		return Visitor{}, err
+	}
+	if v, ok := d.Users[key.String()]; ok {
+		return *v, nil
+	}
+	return Visitor{}, nil
+}
+
+// Update changes one caller's record and saves it
+func (s *Store) Update(key Key, fn func(*Visitor)) (Visitor, error) {
+	if !key.Valid() {
+		return Visitor{}, errors.New("store: key has no alias or user record")
+	}
+
+	var v *Visitor
+	err := s.modify(func(d *data) {
+		v = d.update(key, fn)
+	})
+	if err != nil {
+		return Visitor{}, err
+	}
+	return *v, nil
+}
+
+// UpdateAndEnter changes one caller's record and refreshes their presence
+// on a node in a single write, for callers moving to a new screen
+func (s *Store) UpdateAndEnter(key Key, fn func(*Visitor), p Presence) (Visitor, error) {
+	if !key.Valid() {
+		return Visitor{}, errors.New("store: key has no alias or user record")
+	}
+
+	var v *Visitor
+	err := s.modify(func(d *data) {
+		v = d.update(key, fn)
+		d.enter(p)
+	})
+	if err != nil {
+		return Visitor{}, err
+	}
+	return *v, nil
+}
+
+// update changes one caller's record, adding it if they're new
+func (d *data) update(key Key, fn func(*Visitor)) *Visitor {
+	v := d.Users[key.String()]
+	if v == nil {
+		v = &Visitor{}
+		d.Users[key.String()] = v
+	}
+	if key.Alias != "" {
+		v.Alias = key.Alias
+	}
+	fn(v)
+	return v
+}
+
+// ViewCounts returns how many callers have opened each day of a year,
+// indexed by day (element 0 is unused)
+func (s *Store) ViewCounts(year int) ([]int, error) {
+	d, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int, 26)
+	for _, v := range d.Users {
+		for _, day := range v.Days[year] {
+			if day >= 1 && day < len(counts) {
+				counts[day]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// forgetPresence is how long entries left by callers who dropped without
+// leaving stay in the data file
+const forgetPresence = 24 * time.Hour
+
+// Enter records (or refreshes) a caller's presence on a node
+func (s *Store) Enter(p Presence) error {
+	return s.modify(func(d *data) {
+		d.enter(p)
+	})
+}
+
+// enter records a caller's presence, forgetting stale entries
+func (d *data) enter(p Presence) {
+	if d.Nodes == nil {
+		d.Nodes = make(map[string]*Presence)
+	}
+	for key, old := range d.Nodes {
+		if p.Seen.Sub(old.Seen) > forgetPresence {
+			delete(d.Nodes, key)
+		}
+	}
+	d.Nodes[p.key()] = &p
+}
+
+// Leave removes the caller on a node
+func (s *Store) Leave(bbs string, node int) error {
+	return s.modify(func(d *data) {
+		delete(d.Nodes, Presence{BBS: bbs, Node: node}.key())
+	})
+}
+
+// Present returns the callers seen within maxAge of now, by node
+func (s *Store) Present(now time.Time, maxAge time.Duration) ([]Presence, error) {
+	d, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var present []Presence
+	for _, p := range d.Nodes {
+		if now.Sub(p.Seen) <= maxAge {
+			present = append(present, *p)
+		}
+	}
+	sort.Slice(present, func(i, j int) bool {
+		if present[i].BBS != present[j].BBS {
+			return present[i].BBS < present[j].BBS
+		}
+		return present[i].Node < present[j].Node
+	})
+	return present, nil
+}
+
+// load reads the data file under the lock
+func (s *Store) load() (*data, error) {
+	// Windows can't rename over a file another node is reading, so readers
+	// take the lock too
+	unlock, err := lock(s.path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	defer unlock()
+	return s.read()
+}
+
+// modify changes the data file. It stays locked for the whole
+// read-modify-write so nodes updating it at the same time don't lose each
+// other's changes.
+func (s *Store) modify(fn func(*data)) error {
+	unlock, err := lock(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	defer unlock()
+
+	d, err := s.read()
+	if err != nil {
+		return err
+	}
+	fn(d)
+	return s.write(d)
+}
+
+// read loads the data file; a missing file is an empty store
+func (s *Store) read() (*data, error) {
+	d := &data{Version: fileVersion, Users: make(map[string]*Visitor)}
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(raw, d); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if d.Users == nil {
+		d.Users = make(map[string]*Visitor)
+	}
+	return d, nil
+}
+
+// write saves the data file through a temporary file and a rename, so a
+// crash or a concurrent reader never sees half a file
+func (s *Store) write(d *data) error {
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", s.path, err)
+	}
+	return nil
+}
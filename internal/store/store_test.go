@@ -0,0 +1,171 @@
+package store
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdateAndGet(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key{BBS: "Mistigris", Alias: "SnowQueen"}
+
+	first := time.Date(2025, 12, 1, 20, 0, 0, 0, time.UTC)
+	_, err = s.Update(key, func(v *Visitor) {
+		v.Visit(first)
+		v.ViewDay(2025, 3)
+		v.ViewDay(2025, 1)
+		v.ViewDay(2025, 3)
+		v.ViewYear(2023)
+		v.UnlockYear(2023)
+		v.LastScreen = Position{Year: 2025, Day: 3, Screen: "day"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(key, func(v *Visitor) { v.Visit(first.Add(24 * time.Hour)) }); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names are case-insensitive
+	v, err := s.Get(Key{BBS: "MISTIGRIS", Alias: "snowqueen"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Visits != 2 || !v.FirstVisit.Equal(first) || !v.LastVisit.Equal(first.Add(24*time.Hour)) {
+		t.Errorf("visits = %d, first %v, last %v", v.Visits, v.FirstVisit, v.LastVisit)
+	}
+	if !reflect.DeepEqual(v.Years, []int{2023, 2025}) || !reflect.DeepEqual(v.Days[2025], []int{1, 3}) {
+		t.Errorf("years %v, days %v", v.Years, v.Days)
+	}
+	if !v.Viewed(2025, 3) || v.Viewed(2025, 2) || v.Viewed(2024, 3) {
+		t.Error("Viewed() disagrees with the recorded days")
+	}
+	if !v.HasUnlocked(2023) || v.HasUnlocked(2024) {
+		t.Errorf("Unlocked = %v, want [2023]", v.Unlocked)
+	}
+	if v.LastScreen != (Position{Year: 2025, Day: 3, Screen: "day"}) {
+		t.Errorf("LastScreen = %+v", v.LastScreen)
+	}
+	if got := v.NewestUnopened(2025, 3); got != 2 {
+		t.Errorf("NewestUnopened(2025, 3) = %d, want 2", got)
+	}
+	if got := v.NewestUnopened(2025, 1); got != 0 {
+		t.Errorf("NewestUnopened(2025, 1) = %d, want 0", got)
+	}
+
+	// A record number wins over the alias, so renamed users keep their history
+	if got := (Key{BBS: "Mistigris", Alias: "Renamed", Record: 7}).String(); got != "mistigris/#7" {
+		t.Errorf("String() = %q", got)
+	}
+	if v, _ := s.Get(Key{BBS: "Other BBS", Alias: "SnowQueen"}); v.Visits != 0 {
+		t.Errorf("another BBS's caller shares the record: %+v", v)
+	}
+	if _, err := s.Update(Key{BBS: "Mistigris"}, func(*Visitor) {}); err == nil {
+		t.Error("Update() accepted a key without alias or record")
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	const nodes = 8
+
+	// Each node opens its own store, as separate door processes would
+	var wg sync.WaitGroup
+	for i := 0; i < nodes; i++ {
+		wg.Add(1)
+		go func(node int) {
+			defer wg.Done()
+			s, err := Open(dir)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for j := 0; j < 5; j++ {
+				_, err := s.Update(Key{BBS: "bbs", Record: 1}, func(v *Visitor) {
+					v.Visits++
+					v.ViewDay(2025, node+1)
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	s, _ := Open(dir)
+	v, err := s.Get(Key{BBS: "bbs", Record: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Visits != nodes*5 || len(v.Days[2025]) != nodes {
+		t.Errorf("lost updates: visits %d, days %v", v.Visits, v.Days[2025])
+	}
+}
+
+func TestPresenceAndViewCounts(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 12, 5, 20, 0, 0, 0, time.UTC)
+
+	s.Update(Key{BBS: "bbs", Alias: "a"}, func(v *Visitor) { v.ViewDay(2025, 1); v.ViewDay(2025, 2) })
+	s.Update(Key{BBS: "bbs", Alias: "b"}, func(v *Visitor) { v.ViewDay(2025, 2) })
+	counts, err := s.ViewCounts(2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[1] != 1 || counts[2] != 2 || counts[3] != 0 {
+		t.Errorf("ViewCounts() = %v", counts[1:4])
+	}
+
+	s.Enter(Presence{BBS: "bbs", Node: 2, Alias: "b", Where: "2025 day 2", Since: now, Seen: now})
+	s.Enter(Presence{BBS: "bbs", Node: 1, Alias: "a", Where: "2025 welcome", Since: now, Seen: now.Add(-time.Hour)})
+	s.Enter(Presence{BBS: "bbs", Node: 3, Alias: "c", Where: "2024 welcome", Since: now, Seen: now})
+	if err := s.Leave("BBS", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Node 1 hung up without leaving an hour ago and has gone stale
+	present, err := s.Present(now, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(present) != 1 || present[0].Alias != "b" {
+		t.Errorf("Present() = %+v, want only node 2", present)
+	}
+}
+
+func TestUpdateAndEnter(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 12, 5, 20, 0, 0, 0, time.UTC)
+
+	key := Key{BBS: "bbs", Alias: "a"}
+	v, err := s.UpdateAndEnter(key, func(v *Visitor) { v.ViewDay(2025, 5) },
+		Presence{BBS: "bbs", Node: 1, Alias: "a", Where: "2025 day 5", Since: now, Seen: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Viewed(2025, 5) {
+		t.Errorf("UpdateAndEnter() returned %+v, want day 5 viewed", v)
+	}
+	present, err := s.Present(now, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(present) != 1 || present[0].Where != "2025 day 5" {
+		t.Errorf("Present() = %+v, want node 1 on 2025 day 5", present)
+	}
+	if _, err := s.UpdateAndEnter(Key{BBS: "bbs"}, func(*Visitor) {}, Presence{}); err == nil {
+		t.Error("UpdateAndEnter() with an empty key succeeded")
+	}
+}
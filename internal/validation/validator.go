@@ -5,15 +5,29 @@ import (
 	"io/fs"
 	"path" // Use path instead of filepath for embedded FS (always forward slashes)
 	"strconv"
-	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/clock"
 )
 
 // Validator handles various validation checks
 type Validator struct {
-	baseArtDir string
-	fs         fs.FS
+	baseArtDir  string
+	fs          fs.FS
+	resolver    *calendar.Resolver
+	clock       clock.Clock
+	access      Access
+	ignoreLocks bool
+}
+
+// Access holds the security levels (from the dropfile) needed for parts of
+// the door. A missing entry means anyone may enter.
+type Access struct {
+	Sysop   int            // Level that opens the sysop menu; 0 turns the menu off
+	Years   map[int]int    // Level needed to open a year
+	Screens map[string]int // Level needed for a screen, by name ("info", "members")
 }
 
 // NewValidator creates a new validator
@@ -21,13 +35,25 @@ func NewValidator(embeddedFS fs.FS, baseArtDir string) *Validator {
 	return &Validator{
 		baseArtDir: baseArtDir,
 		fs:         embeddedFS,
+		resolver:   calendar.NewResolver(embeddedFS, baseArtDir),
+		clock:      clock.System,
 	}
 }
 
-// ValidateDate checks if the current date is valid for advent calendar
+// SetResolver shares a calendar resolver with other components
+func (v *Validator) SetResolver(resolver *calendar.Resolver) {
+	v.resolver = resolver
+}
+
+// SetClock sets the clock dates are checked against (for -debug-date and tests)
+func (v *Validator) SetClock(c clock.Clock) {
+	v.clock = c
+}
+
+// ValidateDate checks if the advent season has started under the resolver's
+// unlock rule (December in its time zone, once the first day has opened)
 func (v *Validator) ValidateDate() error {
-	now := time.Now()
-	if now.Month() != time.December || now.Day() < 1 {
+	if !v.resolver.InSeason(v.clock.Now()) {
 		return fmt.Errorf("advent calendar only available in December")
 	}
 	return nil
@@ -54,11 +80,11 @@ func (v *Validator) ValidateArtFiles(year int) error {
 		path.Join(commonDir, "NOTYET.ANS"),
 	}
 
-	// Required year-specific files
+	// Required year-specific files (names may come from the year's manifest)
 	requiredYearFiles := []string{
-		path.Join(yearDir, "WELCOME.ANS"),
-		path.Join(yearDir, "COMEBACK.ANS"),
-		path.Join(yearDir, "GOODBYE.ANS"),
+		v.resolver.ScreenPath(year, calendar.ScreenWelcome),
+		v.resolver.ScreenPath(year, calendar.ScreenComeback),
+		v.resolver.ScreenPath(year, calendar.ScreenGoodbye),
 	}
 
 	// Check required common files
@@ -83,20 +109,11 @@ func (v *Validator) ValidateArtFiles(year int) error {
 	}
 
 	// Check daily art files (warn but don't fail)
-	currentYear := time.Now().Year()
-	maxDay := 25
-	if year == currentYear && time.Now().Month() == time.December {
-		maxDay = time.Now().Day()
-		if maxDay > 25 {
-			maxDay = 25
-		}
-	}
+	maxDay := v.resolver.MaxDay(year, v.clock.Now())
 
 	missingDays := []int{}
 	for day := 1; day <= maxDay; day++ {
-		fileName := fmt.Sprintf("%d_DEC%s.ANS", day, strconv.Itoa(year)[2:])
-		filePath := path.Join(yearDir, fileName)
-		if _, err := fs.Stat(v.fs, filePath); err != nil {
+		if !v.resolver.DayExists(year, day) {
 			missingDays = append(missingDays, day)
 		}
 	}
@@ -114,8 +131,8 @@ func (v *Validator) ValidateArtFiles(year int) error {
 // ValidateYear checks if a year is valid and has art
 func (v *Validator) ValidateYear(year int) error {
 	// Check reasonable year range
-	if year < 2020 || year > 2030 {
-		return fmt.Errorf("year %d is out of valid range (2020-2030)", year)
+	if !calendar.ValidYear(year) {
+		return fmt.Errorf("year %d is out of valid range (%d-%d)", year, calendar.FirstYear, calendar.LastYear)
 	}
 
 	// Check if year directory exists
@@ -127,10 +144,11 @@ func (v *Validator) ValidateYear(year int) error {
 	return nil
 }
 
-// ValidateEmulation checks if ANSI emulation is supported
+// ValidateEmulation checks if the door32 emulation is one we can render
+// (0 = ASCII, 1 = ANSI, 2 = AVATAR)
 func (v *Validator) ValidateEmulation(emulation int) error {
-	if emulation != 1 {
-		return fmt.Errorf("ANSI emulation required (got %d, need 1)", emulation)
+	if emulation < 0 || emulation > 2 {
+		return fmt.Errorf("unsupported emulation (got %d, need 0, 1 or 2)", emulation)
 	}
 	return nil
 }
@@ -146,33 +164,57 @@ func (v *Validator) ValidateTerminalSize(width, height int) error {
 	return nil
 }
 
-// Override time.Now for testing
-var timeNow = time.Now
+// SetAccess sets the security levels parts of the door need
+func (v *Validator) SetAccess(access Access) {
+	v.access = access
+}
 
-// RequireKey checks if a key is required to access a year
-func (v *Validator) RequireKey(year int) bool {
-	// Get current date
-	now := timeNow()
+// SetIgnoreLocks lets the sysop past keys and security levels
+func (v *Validator) SetIgnoreLocks(ignore bool) {
+	v.ignoreLocks = ignore
+}
 
-	// Current year is always accessible without key
-	currentYear := now.Year()
-	if year == currentYear {
-		return false
+// IgnoringLocks reports whether keys and security levels are ignored
+func (v *Validator) IgnoringLocks() bool {
+	return v.ignoreLocks
+}
+
+// IsSysop reports whether a caller's security level opens the sysop menu
+func (v *Validator) IsSysop(level int) bool {
+	return v.access.Sysop > 0 && level >= v.access.Sysop
+}
+
+// YearLevel returns the security level needed to open a year, or 0 when
+// anyone may (or locks are being ignored)
+func (v *Validator) YearLevel(year int) int {
+	if v.ignoreLocks {
+		return 0
 	}
+	return v.access.Years[year]
+}
 
-	// Only 2025 requires keys to access older years
-	if currentYear == 2025 && year < currentYear {
-		return true
+// ScreenLevel returns the security level needed for a screen, or 0 when
+// anyone may (or locks are being ignored)
+func (v *Validator) ScreenLevel(screen string) int {
+	if v.ignoreLocks {
+		return 0
 	}
+	return v.access.Screens[screen]
+}
 
-	// Non-current years don't require keys
-	return false
+// RequireKey reports whether a year is locked behind a key: one is set in
+// the config or the year's manifest, and it isn't the current year (in the
+// unlock time zone), which is always open
+func (v *Validator) RequireKey(year int) bool {
+	if v.ignoreLocks || v.resolver.KeyHash(year) == "" {
+		return false
+	}
+	return year != v.resolver.UnlockRule().In(v.clock.Now()).Year()
 }
 
-// ValidateKey checks if a key is valid for a year
+// ValidateKey reports whether key is the one that unlocks a year
 func (v *Validator) ValidateKey(year int, key string) bool {
-	// For now, any non-empty key is valid
-	return key != ""
+	return v.resolver.CheckKey(year, key)
 }
 
 // GetValidationReport generates a comprehensive validation report
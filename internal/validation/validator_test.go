@@ -4,24 +4,37 @@ import (
 	"testing"
 	"testing/fstest"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/robbiew/advent/internal/calendar"
+	"github.com/robbiew/advent/internal/clock"
 )
 
+// hashKey returns a quick calendar.HashKey(year, key), failing the test on error
+func hashKey(t *testing.T, year int, key string) string {
+	t.Helper()
+	calendar.KeyHashCost = bcrypt.MinCost
+	hash, err := calendar.HashKey(year, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
 func TestRequireKey(t *testing.T) {
-	// Create a mock filesystem
-	mockFS := fstest.MapFS{}
+	// 2023 has a key in its manifest, 2024 one from the config, 2022 none
+	manifest := `{"days": [], "key": "` + hashKey(t, 2023, "frosty") + `"}`
+	mockFS := fstest.MapFS{"art/2023/calendar.json": {Data: []byte(manifest)}}
 
 	// Create a validator
 	validator := NewValidator(mockFS, "art")
-
-	// Use monkey patching to override time.Now for testing
-	// Save the original function
-	originalTimeNow := timeNow
-	defer func() { timeNow = originalTimeNow }()
+	resolver := calendar.NewResolver(mockFS, "art")
+	resolver.SetKeyHashes(map[int]string{2024: hashKey(t, 2024, "rudolph")})
+	validator.SetResolver(resolver)
 
 	// Test with current year set to 2025
-	timeNow = func() time.Time {
-		return time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
-	}
+	validator.SetClock(clock.Fixed(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)))
 
 	// Test cases for 2025
 	testCases := []struct {
@@ -30,8 +43,9 @@ func TestRequireKey(t *testing.T) {
 		expected bool
 	}{
 		{"Current year (2025) doesn't require key", 2025, false},
-		{"Older year (2024) requires key when current year is 2025", 2024, true},
-		{"Older year (2023) requires key when current year is 2025", 2023, true},
+		{"Older year (2024) requires its configured key", 2024, true},
+		{"Older year (2023) requires its manifest key", 2023, true},
+		{"Older year (2022) without a key is open", 2022, false},
 	}
 
 	for _, tc := range testCases {
@@ -44,9 +58,7 @@ func TestRequireKey(t *testing.T) {
 	}
 
 	// Now test with current year set to 2024
-	timeNow = func() time.Time {
-		return time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
-	}
+	validator.SetClock(clock.Fixed(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)))
 
 	// Additional test cases for 2024
 	additionalTestCases := []struct {
@@ -55,7 +67,7 @@ func TestRequireKey(t *testing.T) {
 		expected bool
 	}{
 		{"Current year (2024) doesn't require key", 2024, false},
-		{"Older year (2023) doesn't require key when current year is 2024", 2023, false},
+		{"Older year (2023) still requires its key when current year is 2024", 2023, true},
 	}
 
 	for _, tc := range additionalTestCases {
@@ -66,4 +78,60 @@ func TestRequireKey(t *testing.T) {
 			}
 		})
 	}
+
+	if !validator.ValidateKey(2024, "Rudolph") || validator.ValidateKey(2024, "frosty") || validator.ValidateKey(2022, "anything") {
+		t.Error("ValidateKey() should accept only the year's own key")
+	}
+}
+
+func TestValidateDate(t *testing.T) {
+	validator := NewValidator(fstest.MapFS{}, "art")
+
+	testCases := []struct {
+		name  string
+		now   time.Time
+		valid bool
+	}{
+		{"Nov 30", time.Date(2025, 11, 30, 12, 0, 0, 0, time.Local), false},
+		{"Dec 1 at 23:59", time.Date(2025, 12, 1, 23, 59, 0, 0, time.Local), true},
+		{"Dec 31", time.Date(2025, 12, 31, 9, 0, 0, 0, time.Local), true},
+		{"Jan 2", time.Date(2026, 1, 2, 12, 0, 0, 0, time.Local), false},
+	}
+	for _, tc := range testCases {
+		validator.SetClock(clock.Fixed(tc.now))
+		if err := validator.ValidateDate(); (err == nil) != tc.valid {
+			t.Errorf("%s: ValidateDate() = %v, want valid %v", tc.name, err, tc.valid)
+		}
+	}
+}
+
+func TestAccess(t *testing.T) {
+	manifest := `{"days": [], "key": "` + hashKey(t, 2023, "frosty") + `"}`
+	validator := NewValidator(fstest.MapFS{"art/2023/calendar.json": {Data: []byte(manifest)}}, "art")
+	validator.SetClock(clock.Fixed(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)))
+	validator.SetAccess(Access{
+		Sysop:   90,
+		Years:   map[int]int{2023: 20},
+		Screens: map[string]int{"members": 10},
+	})
+
+	if validator.IsSysop(89) || !validator.IsSysop(90) {
+		t.Error("IsSysop() should need level 90")
+	}
+	if validator.YearLevel(2023) != 20 || validator.YearLevel(2024) != 0 {
+		t.Errorf("YearLevel() = %d, %d", validator.YearLevel(2023), validator.YearLevel(2024))
+	}
+	if validator.ScreenLevel("members") != 10 || validator.ScreenLevel("info") != 0 {
+		t.Error("ScreenLevel() should only restrict members")
+	}
+
+	// The sysop can look past every lock
+	validator.SetIgnoreLocks(true)
+	if validator.YearLevel(2023) != 0 || validator.ScreenLevel("members") != 0 || validator.RequireKey(2023) {
+		t.Error("ignoring locks should open years, screens and keys")
+	}
+
+	if NewValidator(fstest.MapFS{}, "art").IsSysop(255) {
+		t.Error("the sysop menu should be off without a sysop level")
+	}
 }
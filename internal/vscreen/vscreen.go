@@ -0,0 +1,467 @@
+// Package vscreen interprets an ANSI art stream onto a grid of character
+// cells, so art can be cropped, scrolled and re-emitted line by line no matter
+// how its bytes moved the cursor around.
+package vscreen
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Attr holds the text attributes of a cell
+type Attr uint8
+
+const (
+	Bold Attr = 1 << iota
+	Blink
+	Reverse
+)
+
+// Default colors (ANSI color numbers, 0-7)
+const (
+	DefaultFg = 7
+	DefaultBg = 0
+)
+
+// Cell is one character position on the screen
+type Cell struct {
+	Glyph rune // CP437 byte value, or a Unicode code point when UTF8 is set
+	Fg    uint8
+	Bg    uint8
+	Attrs Attr
+}
+
+// MaxRows caps how tall a screen grows, so a stray cursor movement in a bad
+// art file can't allocate rows without end. Anything below is drawn on the
+// last row.
+const MaxRows = 5000
+
+// MaxWidth caps how wide a screen is, whatever width a file's SAUCE record
+// claims; each row is allocated at the full width
+const MaxWidth = 255
+
+// maxParam caps CSI parameters; nothing on a screen needs larger
+const maxParam = 9999
+
+// blank is an empty cell with the default colors
+var blank = Cell{Glyph: ' ', Fg: DefaultFg, Bg: DefaultBg}
+
+// style returns the cell's colors and attributes without its glyph
+func (c Cell) style() Cell {
+	c.Glyph = 0
+	return c
+}
+
+// isBlank reports whether the cell would look like an empty default cell
+func (c Cell) isBlank() bool {
+	return c.Glyph == ' ' && c.Bg == DefaultBg && c.Attrs&Reverse == 0
+}
+
+// Screen is a grid of cells Width columns wide. Rows are added as content is
+// drawn below the last one, so art taller than the terminal keeps every line.
+type Screen struct {
+	Width int
+	UTF8  bool // Input and output are UTF-8 instead of CP437 bytes
+
+	rows           [][]Cell
+	x, y           int
+	savedX, savedY int
+	wrapNeeded     bool // Last column was written; wrap before the next character
+	pen            Cell
+
+	pending []byte // Partial escape sequence or UTF-8 character between writes
+	eof     bool   // A ^Z was seen; the rest is SAUCE or padding
+}
+
+// New creates an empty screen of the given width, up to MaxWidth
+func New(width int) *Screen {
+	if width <= 0 {
+		width = 80
+	}
+	if width > MaxWidth {
+		width = MaxWidth
+	}
+	return &Screen{Width: width, pen: blank}
+}
+
+// Height returns the number of rows drawn so far, ignoring blank rows at the bottom
+func (s *Screen) Height() int {
+	h := len(s.rows)
+	for h > 0 && s.Len(h-1) == 0 {
+		h--
+	}
+	return h
+}
+
+// Cell returns the cell at x, y (blank outside the drawn area)
+func (s *Screen) Cell(x, y int) Cell {
+	if y < 0 || y >= len(s.rows) || x < 0 || x >= s.Width {
+		return blank
+	}
+	return s.rows[y][x]
+}
+
+// Len returns the width of row y without trailing blank cells
+func (s *Screen) Len(y int) int {
+	if y < 0 || y >= len(s.rows) {
+		return 0
+	}
+	row := s.rows[y]
+	n := len(row)
+	for n > 0 && row[n-1].isBlank() {
+		n--
+	}
+	return n
+}
+
+// Write interprets ANSI data onto the screen. Data after a DOS EOF (^Z) is ignored.
+func (s *Screen) Write(p []byte) (int, error) {
+	if s.eof {
+		return len(p), nil
+	}
+	data := p
+	if len(s.pending) > 0 {
+		data = append(s.pending, p...)
+		s.pending = nil
+	}
+
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch b {
+		case 0x1A:
+			s.eof = true
+			return len(p), nil
+		case 0x1B:
+			end, ok := s.escape(data, i)
+			if !ok {
+				s.pending = append([]byte(nil), data[i:]...)
+				return len(p), nil
+			}
+			i = end
+		case '\r':
+			s.moveTo(0, s.y)
+		case '\n':
+			s.moveTo(s.x, s.y+1)
+		case '\b':
+			s.moveTo(s.x-1, s.y)
+		case '\t':
+			s.moveTo((s.x/8+1)*8, s.y)
+		case 0x07: // Bell
+		default:
+			if s.UTF8 && b >= utf8.RuneSelf {
+				if !utf8.FullRune(data[i:]) {
+					s.pending = append([]byte(nil), data[i:]...)
+					return len(p), nil
+				}
+				r, size := utf8.DecodeRune(data[i:])
+				s.put(r)
+				i += size - 1
+				continue
+			}
+			s.put(rune(b))
+		}
+	}
+	return len(p), nil
+}
+
+// escape handles the sequence starting at data[i] and returns the index of
+// its last byte, or false when the sequence is cut off
+func (s *Screen) escape(data []byte, i int) (int, bool) {
+	if i+1 >= len(data) {
+		return i, false
+	}
+	switch data[i+1] {
+	case '7':
+		s.savedX, s.savedY = s.x, s.y
+		return i + 1, true
+	case '8':
+		s.moveTo(s.savedX, s.savedY)
+		return i + 1, true
+	case '[':
+	default:
+		return i + 1, true // Other two-byte sequences don't draw anything
+	}
+
+	end := i + 2
+	for end < len(data) && (data[end] < 0x40 || data[end] > 0x7E) {
+		end++
+	}
+	if end >= len(data) {
+		return i, false
+	}
+	s.csi(string(data[i+2:end]), data[end])
+	return end, true
+}
+
+// csi applies a control sequence with the given parameters and final byte
+func (s *Screen) csi(raw string, final byte) {
+	params := ParseParams(raw)
+	n := Param(params, 0, 1)
+
+	switch final {
+	case 'A':
+		s.moveTo(s.x, s.y-n)
+	case 'B':
+		s.moveTo(s.x, s.y+n)
+	case 'C':
+		s.moveTo(s.x+n, s.y)
+	case 'D':
+		s.moveTo(s.x-n, s.y)
+	case 'H', 'f':
+		s.moveTo(Param(params, 1, 1)-1, Param(params, 0, 1)-1)
+	case 'J':
+		s.eraseScreen(Param(params, 0, 0))
+	case 'K':
+		s.eraseLine(Param(params, 0, 0))
+	case 'm':
+		s.sgr(params)
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.moveTo(s.savedX, s.savedY)
+	}
+	// Modes (h/l), reports (n) and the rest don't change the picture
+}
+
+// sgr applies Select Graphic Rendition parameters to the pen
+func (s *Screen) sgr(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+	for _, code := range params {
+		switch {
+		case code == 0:
+			s.pen = blank
+		case code == 1:
+			s.pen.Attrs |= Bold
+		case code == 22:
+			s.pen.Attrs &^= Bold
+		case code == 5 || code == 6:
+			s.pen.Attrs |= Blink
+		case code == 25:
+			s.pen.Attrs &^= Blink
+		case code == 7:
+			s.pen.Attrs |= Reverse
+		case code == 27:
+			s.pen.Attrs &^= Reverse
+		case code >= 30 && code <= 37:
+			s.pen.Fg = uint8(code - 30)
+		case code == 39:
+			s.pen.Fg = DefaultFg
+		case code >= 40 && code <= 47:
+			s.pen.Bg = uint8(code - 40)
+		case code == 49:
+			s.pen.Bg = DefaultBg
+		case code >= 90 && code <= 97:
+			s.pen.Fg = uint8(code - 90)
+			s.pen.Attrs |= Bold
+		case code >= 100 && code <= 107:
+			// High-intensity backgrounds are blink + background (ICE colors)
+			s.pen.Bg = uint8(code - 100)
+			s.pen.Attrs |= Blink
+		}
+	}
+}
+
+// eraseScreen handles ED: 0 = to end of screen, 1 = to cursor, 2 = all and home (as ANSI.SYS does)
+func (s *Screen) eraseScreen(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.y + 1; y < len(s.rows); y++ {
+			s.fill(y, 0, s.Width)
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.y && y < len(s.rows); y++ {
+			s.fill(y, 0, s.Width)
+		}
+	case 2:
+		s.rows = nil
+		s.moveTo(0, 0)
+	}
+}
+
+// eraseLine handles EL: 0 = to end of line, 1 = to cursor, 2 = whole line
+func (s *Screen) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		s.fill(s.y, s.x, s.Width)
+	case 1:
+		s.fill(s.y, 0, s.x+1)
+	case 2:
+		s.fill(s.y, 0, s.Width)
+	}
+}
+
+// fill blanks cells [from, to) of row y with the pen's background
+func (s *Screen) fill(y, from, to int) {
+	c := s.pen.style()
+	c.Glyph = ' '
+	c.Attrs &^= Bold
+	row := s.row(y)
+	for x := from; x < to && x < len(row); x++ {
+		row[x] = c
+	}
+}
+
+// moveTo positions the cursor, clamped to the left and top edges, the width
+// and MaxRows
+func (s *Screen) moveTo(x, y int) {
+	if x < 0 {
+		x = 0
+	}
+	if x >= s.Width {
+		x = s.Width - 1
+	}
+	if y < 0 {
+		y = 0
+	}
+	if y >= MaxRows {
+		y = MaxRows - 1
+	}
+	s.x, s.y = x, y
+	s.wrapNeeded = false
+}
+
+// put draws a glyph with the pen and advances the cursor, wrapping like a terminal
+func (s *Screen) put(r rune) {
+	if s.wrapNeeded {
+		s.moveTo(0, s.y+1)
+	}
+	c := s.pen
+	c.Glyph = r
+	s.row(s.y)[s.x] = c
+
+	if s.x == s.Width-1 {
+		s.wrapNeeded = true
+	} else {
+		s.x++
+	}
+}
+
+// row returns row y, adding blank rows down to it as needed
+func (s *Screen) row(y int) []Cell {
+	for len(s.rows) <= y {
+		row := make([]Cell, s.Width)
+		for i := range row {
+			row[i] = blank
+		}
+		s.rows = append(s.rows, row)
+	}
+	return s.rows[y]
+}
+
+// Scale returns a copy of the drawn area resized to width x height by
+// sampling cells, for thumbnails of art
+func (s *Screen) Scale(width, height int) *Screen {
+	scaled := New(width)
+	scaled.UTF8 = s.UTF8
+	srcHeight := s.Height()
+	for y := 0; y < height; y++ {
+		row := scaled.row(y)
+		for x := range row {
+			row[x] = s.Cell(x*s.Width/scaled.Width, y*srcHeight/height)
+		}
+	}
+	return scaled
+}
+
+// Text returns the glyphs of row y up to cols columns, trailing blanks removed
+func (s *Screen) Text(y, cols int) string {
+	n := s.Len(y)
+	if n > cols {
+		n = cols
+	}
+	var b strings.Builder
+	for x := 0; x < n; x++ {
+		s.writeGlyph(&b, s.rows[y][x].Glyph)
+	}
+	return b.String()
+}
+
+// ANSI returns row y up to cols columns as text with SGR color codes. Each
+// row starts from reset attributes and ends with a reset, so rows can be
+// printed in any order.
+func (s *Screen) ANSI(y, cols int) string {
+	n := s.Len(y)
+	if n > cols {
+		n = cols
+	}
+	var b strings.Builder
+	current := blank.style()
+	for x := 0; x < n; x++ {
+		c := s.rows[y][x]
+		if style := c.style(); style != current {
+			b.WriteString(transition(current, style))
+			current = style
+		}
+		s.writeGlyph(&b, c.Glyph)
+	}
+	if current != blank.style() {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// writeGlyph writes one glyph in the screen's output encoding
+func (s *Screen) writeGlyph(b *strings.Builder, r rune) {
+	if s.UTF8 {
+		b.WriteRune(r)
+	} else {
+		b.WriteByte(byte(r))
+	}
+}
+
+// transition returns the SGR sequence that changes from one style to another
+func transition(from, to Cell) string {
+	var codes []string
+	// Attributes can only be switched off portably with a full reset
+	if from.Attrs&^to.Attrs != 0 {
+		codes = append(codes, "0")
+		from = blank.style()
+	}
+	if to.Attrs&Bold != 0 && from.Attrs&Bold == 0 {
+		codes = append(codes, "1")
+	}
+	if to.Attrs&Blink != 0 && from.Attrs&Blink == 0 {
+		codes = append(codes, "5")
+	}
+	if to.Attrs&Reverse != 0 && from.Attrs&Reverse == 0 {
+		codes = append(codes, "7")
+	}
+	if to.Fg != from.Fg {
+		codes = append(codes, strconv.Itoa(30+int(to.Fg)))
+	}
+	if to.Bg != from.Bg {
+		codes = append(codes, strconv.Itoa(40+int(to.Bg)))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// ParseParams splits CSI parameters, ignoring private markers such as '?' and
+// '=' and capping each at maxParam
+func ParseParams(raw string) []int {
+	raw = strings.TrimLeft(raw, "<=>?")
+	if raw == "" {
+		return nil
+	}
+	fields := strings.Split(raw, ";")
+	params := make([]int, len(fields))
+	for i, f := range fields {
+		params[i], _ = strconv.Atoi(f)
+		if params[i] > maxParam {
+			params[i] = maxParam
+		}
+	}
+	return params
+}
+
+// Param returns parameter i, or def when it is missing or zero
+func Param(params []int, i, def int) int {
+	if i < len(params) && params[i] > 0 {
+		return params[i]
+	}
+	return def
+}
@@ -0,0 +1,124 @@
+package vscreen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func draw(width int, data string) *Screen {
+	s := New(width)
+	s.Write([]byte(data))
+	return s
+}
+
+func TestCursorMovement(t *testing.T) {
+	s := draw(10, "\x1b[2;3Hab\x1b[1Ac\x1b[3Dd\x1b7\x1b[5;1He\x1b8f\x1b[sg\x1b[1;1H\x1b[uh")
+
+	want := []string{
+		"  dfh",
+		"  ab",
+		"",
+		"",
+		"e",
+	}
+	if got := s.Height(); got != len(want) {
+		t.Fatalf("Height() = %d, want %d", got, len(want))
+	}
+	for y, line := range want {
+		if got := s.Text(y, 10); got != line {
+			t.Errorf("row %d = %q, want %q", y, got, line)
+		}
+	}
+}
+
+func TestWrapAndErase(t *testing.T) {
+	// A full row wraps only when the next character arrives
+	s := draw(4, "abcd\r\nefghi\x1b[1;2H\x1b[K")
+	for y, line := range []string{"a", "efgh", "i"} {
+		if got := s.Text(y, 4); got != line {
+			t.Errorf("row %d = %q, want %q", y, got, line)
+		}
+	}
+
+	// A full clear homes the cursor and forgets earlier rows
+	s = draw(4, "ab\r\ncd\x1b[2Jx")
+	if s.Height() != 1 || s.Text(0, 4) != "x" {
+		t.Errorf("after clear: height %d, row 0 %q", s.Height(), s.Text(0, 4))
+	}
+}
+
+func TestSplitWrites(t *testing.T) {
+	s := New(10)
+	s.UTF8 = true
+	for _, part := range []string{"\x1b[", "1;31m", "\xe2\x96", "\x88", "x\x1a", "ignored"} {
+		s.Write([]byte(part))
+	}
+	if got := s.Text(0, 10); got != "█x" {
+		t.Errorf("row 0 = %q, want %q", got, "█x")
+	}
+	if c := s.Cell(0, 0); c.Fg != 1 || c.Attrs != Bold {
+		t.Errorf("cell = %+v, want bold red", c)
+	}
+}
+
+func TestANSI(t *testing.T) {
+	s := draw(80, "\x1b[1;31mab\x1b[44mc\x1b[0m  d\x1b[42m  \x1b[0m   ")
+
+	want := "\x1b[1;31mab\x1b[44mc\x1b[0m  d\x1b[42m  \x1b[0m"
+	if got := s.ANSI(0, 80); got != want {
+		t.Errorf("ANSI() = %q, want %q", got, want)
+	}
+	if got := s.ANSI(0, 2); got != "\x1b[1;31mab\x1b[0m" {
+		t.Errorf("cropped ANSI() = %q", got)
+	}
+	if got := s.Len(0); got != 8 {
+		t.Errorf("Len() = %d, want 8 (colored background counts)", got)
+	}
+}
+
+func TestCursorPositionedFullScreen(t *testing.T) {
+	// Art drawn with cursor positioning and no line breaks, reaching the
+	// bottom-right corner of an 80x25 screen
+	var art strings.Builder
+	for row := 1; row <= 25; row++ {
+		fmt.Fprintf(&art, "\x1b[%d;1H%s", row, strings.Repeat("#", 80))
+	}
+	s := draw(80, art.String())
+	if s.Height() != 25 {
+		t.Fatalf("Height() = %d, want 25", s.Height())
+	}
+	if s.Len(24) != 80 {
+		t.Errorf("last row length = %d, want 80", s.Len(24))
+	}
+}
+
+func TestScale(t *testing.T) {
+	s := draw(8, "ab\x1b[31mcd\x1b[0mefgh\r\n12345678\r\nABCDEFGH\r\nijklmnop")
+
+	thumb := s.Scale(4, 2)
+	for y, line := range []string{"aceg", "ACEG"} {
+		if got := thumb.Text(y, 4); got != line {
+			t.Errorf("row %d = %q, want %q", y, got, line)
+		}
+	}
+	if got := thumb.Cell(1, 0); got.Glyph != 'c' || got.Fg != 1 {
+		t.Errorf("Cell(1, 0) = %+v, want a red c", got)
+	}
+}
+
+func TestHugeMovesAreClamped(t *testing.T) {
+	s := draw(80, "a\x1b[99999999Hb\x1b[99999999Bc\n\nd")
+	if got := s.Height(); got != MaxRows {
+		t.Fatalf("Height() = %d, want %d", got, MaxRows)
+	}
+	if got := s.Text(MaxRows-1, 80); got != "bcd" {
+		t.Errorf("last row = %q, want %q", got, "bcd")
+	}
+	if got := New(65535).Width; got != MaxWidth {
+		t.Errorf("New(65535).Width = %d, want %d", got, MaxWidth)
+	}
+	if got := ParseParams("12;99999999;-1"); got[0] != 12 || got[1] != maxParam || got[2] != -1 {
+		t.Errorf("ParseParams() = %v, want [12 %d -1]", got, maxParam)
+	}
+}
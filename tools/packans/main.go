@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/robbiew/advent/internal/sauce"
 )
 
 var missingAnsData []byte
@@ -35,7 +37,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Warning: Cannot load %s\n", missingPath)
 		missingAnsData = []byte{}
 	} else {
-		missingAnsData = stripSAUCE(missingAnsData)
+		missingAnsData = sauce.Strip(missingAnsData)
 		fmt.Printf("Loaded MISSING.ANS (%d bytes)\n", len(missingAnsData))
 	}
 
@@ -77,7 +79,7 @@ func main() {
 			}
 		} else {
 			// Existing art - just strip SAUCE, NO date overlay
-			data = stripSAUCE(d)
+			data = sauce.Strip(d)
 			fmt.Printf("Screen %2d: %s (%d bytes)\n", i, baseName, len(data))
 		}
 
@@ -103,36 +105,6 @@ func main() {
 	fmt.Printf("\nCreated %s: %d screens, %d bytes\n", outputFile, numScreens, currentOffset)
 }
 
-func stripSAUCE(data []byte) []byte {
-	markers := [][]byte{[]byte("SAUCE00"), []byte("COMNT")}
-	minIdx := len(data)
-
-	for _, marker := range markers {
-		for i := 0; i+len(marker) <= len(data); i++ {
-			match := true
-			for j := 0; j < len(marker); j++ {
-				if data[i+j] != marker[j] {
-					match = false
-					break
-				}
-			}
-			if match && i < minIdx {
-				if i > 0 && data[i-1] == 0x1A {
-					minIdx = i - 1
-				} else {
-					minIdx = i
-				}
-				break
-			}
-		}
-	}
-
-	if minIdx < len(data) {
-		return data[:minIdx]
-	}
-	return data
-}
-
 func isDailyArt(filename string) bool {
 	// Daily art files: N_DECYY.ANS or NN_DECYY.ANS
 	base := filepath.Base(filename)